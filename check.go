@@ -0,0 +1,278 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/collector"
+	"github.com/amirhnajafiz/localsight/internal/configs"
+	"github.com/amirhnajafiz/localsight/internal/kube"
+	"github.com/amirhnajafiz/localsight/internal/sources"
+	"github.com/amirhnajafiz/localsight/pkg/fetch"
+)
+
+// checker prints the result of each check with a hint that explains the failures.
+type checker struct {
+	out    io.Writer
+	failed int
+}
+
+// ok prints a passed check.
+func (c *checker) ok(name, format string, args ...any) {
+	fmt.Fprintf(c.out, "[ok]   %s: %s\n", name, fmt.Sprintf(format, args...))
+}
+
+// fail prints a failed check and the hint to fix it.
+func (c *checker) fail(name string, err error, hint string) {
+	c.failed++
+
+	fmt.Fprintf(c.out, "[fail] %s: %v\n", name, err)
+	if hint != "" {
+		fmt.Fprintf(c.out, "       hint: %s\n", hint)
+	}
+}
+
+// runCheck verifies that the configured summary source is reachable with the
+// configured credentials, and explains the failures.
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags := configs.NewFlags(fs, os.Getenv("LSE_CONFIG_FILE"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := &checker{out: os.Stdout}
+
+	conf, err := configs.LoadConfig(flags.Path, flags.Values())
+	if err != nil {
+		c.fail("config", err, "fix the configuration file, the LSE_ environment variables or the flags")
+		return errors.New("failed checks: 1")
+	}
+
+	c.ok("config", "mode %s, source %s", conf.Mode, conf.Source)
+
+	switch {
+	case conf.Mode == configs.ModeCluster || conf.Source == sources.SourceProxy:
+		c.checkAPIServer(conf)
+	case conf.Source == sources.SourceKubelet:
+		c.checkKubelet(conf)
+	case conf.Source == sources.SourceCRI:
+		c.checkCRI(conf)
+	case conf.Source == sources.SourceFile:
+		c.checkFile(conf)
+	case conf.Source == sources.SourceReplay:
+		c.checkReplay(conf)
+	}
+
+	if c.failed > 0 {
+		return fmt.Errorf("failed checks: %d", c.failed)
+	}
+
+	return nil
+}
+
+// checkKubelet checks the client certificate and the kubelet summary endpoint.
+func (c *checker) checkKubelet(conf *configs.Config) {
+	cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+	if err != nil {
+		hint := "set --cert-file and --key-file to the kubelet client certificate, usually /var/lib/kubelet/pki/kubelet-client-current.pem, and mount it from the host"
+		if errors.Is(err, os.ErrPermission) {
+			hint = "the exporter cannot read the certificate, run it as root or grant read access to the file"
+		}
+
+		c.fail("client certificate", err, hint)
+		return
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		c.fail("client certificate", err, "the certificate file does not hold a valid x509 certificate")
+		return
+	}
+
+	if time.Now().After(leaf.NotAfter) {
+		c.fail("client certificate", fmt.Errorf("expired on %s", leaf.NotAfter.Format(time.RFC3339)), "the kubelet rotates its client certificate, point --cert-file to the current one")
+		return
+	}
+
+	c.ok("client certificate", "subject %s, expires on %s", leaf.Subject.CommonName, leaf.NotAfter.Format(time.RFC3339))
+
+	src, err := sources.NewKubelet(conf.K8SLocalAPI, conf.CertFile, conf.KeyFile)
+	if err != nil {
+		c.fail("kubelet endpoint", err, "set --k8s-local-api to a valid URL, e.g. https://localhost:10250/stats/summary")
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodGet, src.Endpoint(), nil)
+	if err != nil {
+		c.fail("kubelet endpoint", err, "")
+		return
+	}
+
+	resp, err := fetch.GET(req, conf.CertFile, conf.KeyFile)
+	if err != nil {
+		c.fail("kubelet endpoint", err, connectionHint(err, "the kubelet"))
+		return
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.ok("kubelet endpoint", "%s is reachable", src.Endpoint())
+	case http.StatusUnauthorized:
+		c.fail("kubelet endpoint", fmt.Errorf("status %d", resp.StatusCode), "the kubelet rejected the client certificate, check that it is signed by the cluster CA")
+		return
+	case http.StatusForbidden:
+		c.fail("kubelet endpoint", fmt.Errorf("status %d", resp.StatusCode), "the certificate user is not allowed to read the node stats, grant it get on nodes/stats")
+		return
+	default:
+		c.fail("kubelet endpoint", fmt.Errorf("status %d", resp.StatusCode), "check that the endpoint is the kubelet /stats/summary path")
+		return
+	}
+
+	c.checkSummary(conf, src)
+}
+
+// checkAPIServer checks the service account credentials and the node proxy access.
+func (c *checker) checkAPIServer(conf *configs.Config) {
+	client, err := kube.NewInCluster(conf.APIServer)
+	if err != nil {
+		c.fail("api server", err, "run the exporter in a pod with a service account token, or set --api-server")
+		return
+	}
+
+	c.checkNodes(conf, client)
+}
+
+// checkNodes checks the node list and the node proxy access of the API server.
+func (c *checker) checkNodes(conf *configs.Config, client *kube.Client) {
+	nodes, err := client.ListNodes()
+	if err != nil {
+		c.fail("list nodes", err, apiHint(err, connectionHint(err, "the API server")))
+		return
+	}
+
+	c.ok("list nodes", "%d nodes in the cluster", len(nodes))
+
+	node := conf.NodeName
+	if node == "" && len(nodes) > 0 {
+		node = nodes[0]
+	}
+
+	if node == "" {
+		c.fail("node proxy", errors.New("no node to check"), "set --node-name")
+		return
+	}
+
+	c.checkSummary(conf, sources.NewProxy(client, node))
+}
+
+// checkCRI checks the runtime socket.
+func (c *checker) checkCRI(conf *configs.Config) {
+	if path, ok := strings.CutPrefix(conf.CRIEndpoint, "unix://"); ok {
+		if _, err := os.Stat(path); err != nil {
+			c.fail("cri socket", err, "mount the runtime socket from the host, e.g. /run/containerd/containerd.sock, and set --cri-endpoint")
+			return
+		}
+
+		c.ok("cri socket", "%s exists", path)
+	}
+
+	src, err := sources.NewCRI(conf.NodeName, conf.CRIEndpoint, conf.Interval)
+	if err != nil {
+		c.fail("cri endpoint", err, "set --cri-endpoint to the runtime socket, e.g. unix:///run/containerd/containerd.sock")
+		return
+	}
+	defer src.Close()
+
+	c.checkSummary(conf, src)
+}
+
+// checkFile checks the summary file.
+func (c *checker) checkFile(conf *configs.Config) {
+	if _, err := os.Stat(conf.SourceFile); err != nil {
+		c.fail("summary file", err, "set --source-file to a kubelet summary JSON file")
+		return
+	}
+
+	c.checkSummary(conf, sources.NewFile(conf.SourceFile))
+}
+
+// checkReplay checks the archive of the replay source by reading its first record.
+func (c *checker) checkReplay(conf *configs.Config) {
+	if _, err := os.Stat(conf.SourceFile); err != nil {
+		c.fail("archive", err, "set --source-file to an archive written by 'localsight record'")
+		return
+	}
+
+	src := sources.NewReplay(conf.SourceFile, 0, false)
+	defer src.Close()
+
+	c.checkSummary(conf, src)
+}
+
+// checkSummary fetches a summary from the source and reports its content.
+func (c *checker) checkSummary(conf *configs.Config, src sources.Source) {
+	col := &collector.Collector{
+		NodeName: conf.NodeName,
+		Source:   src,
+		Options:  collector.NewOptions(settings(conf)),
+	}
+
+	snapshot, meta, err := col.Snapshot()
+	if err != nil {
+		c.fail("summary", err, apiHint(err, "the summary could not be read or decoded, run 'localsight once --debug' for details"))
+		return
+	}
+
+	c.ok("summary", "%d samples from %s in %s", len(snapshot.Samples), meta.Endpoint, meta.Latency.Round(time.Millisecond))
+}
+
+// connectionHint explains the network and TLS errors of a connection to the target.
+func connectionHint(err error, target string) string {
+	var (
+		dnsErr  *net.DNSError
+		certErr *tls.CertificateVerificationError
+		netErr  net.Error
+	)
+
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return target + " refused the connection, check the address and the port, and that the exporter uses the host network when connecting to localhost"
+	case errors.As(err, &dnsErr):
+		return "the host name of " + target + " cannot be resolved"
+	case errors.As(err, &certErr):
+		return "the certificate of " + target + " is not trusted, check the CA bundle"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "the connection to " + target + " timed out, check the network policies and the firewall"
+	default:
+		return ""
+	}
+}
+
+// apiHint explains the status errors of the API server.
+func apiHint(err error, fallback string) string {
+	var se *kube.StatusError
+	if !errors.As(err, &se) {
+		return fallback
+	}
+
+	switch se.Code {
+	case http.StatusUnauthorized:
+		return "the service account token was rejected, check that it is mounted and not expired"
+	case http.StatusForbidden:
+		return "the service account is not allowed, grant it get and list on nodes and get on nodes/proxy (see the chart RBAC)"
+	default:
+		return fallback
+	}
+}
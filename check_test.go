@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amirhnajafiz/localsight/internal/configs"
+	"github.com/amirhnajafiz/localsight/internal/kube"
+)
+
+func TestCheckAPIServerHints(t *testing.T) {
+	forbidden := "the service account is not allowed"
+
+	tests := []struct {
+		name  string
+		proxy bool
+		want  string
+	}{
+		{name: "list nodes", want: "[fail] list nodes"},
+		{name: "node proxy", proxy: true, want: "[fail] summary"},
+	}
+
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := configs.LoadConfig("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.NodeName = "node"
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the node list is allowed when only the node proxy is forbidden
+			if tt.proxy && r.URL.Path == "/api/v1/nodes" {
+				_, _ = w.Write([]byte(`{"items":[{"metadata":{"name":"node"}}]}`))
+				return
+			}
+
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}))
+
+		var out bytes.Buffer
+		c := &checker{out: &out}
+		c.checkNodes(conf, kube.NewClient(server.URL, token, server.Client()))
+		server.Close()
+
+		if c.failed != 1 || !strings.Contains(out.String(), tt.want) || !strings.Contains(out.String(), forbidden) {
+			t.Errorf("%s: expected the forbidden hint after %q, got:\n%s", tt.name, tt.want, out.String())
+		}
+	}
+}
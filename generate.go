@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/amirhnajafiz/localsight/internal/generate"
+)
+
+// runGenerate writes a PrometheusRule manifest or a Grafana dashboard to stdout,
+// generated from the metric definitions.
+func runGenerate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: localsight generate <rules|dashboard> [flags]")
+	}
+
+	fs := flag.NewFlagSet("generate "+args[0], flag.ContinueOnError)
+
+	switch args[0] {
+	case "rules":
+		opts := generate.RulesOptions{Labels: map[string]string{}}
+		release := fs.String("release", "", "value of the release label, used by the Prometheus operator rule selector")
+		fs.StringVar(&opts.Name, "name", "localsight", "name of the PrometheusRule")
+		fs.StringVar(&opts.Namespace, "namespace", "", "namespace of the PrometheusRule")
+		fs.Float64Var(&opts.NearLimit, "near-limit", 0.9, "used fraction of the capacity that triggers the near-limit alerts")
+		fs.StringVar(&opts.FillWindow, "fill-window", "6h", "range used to predict the fill rate")
+		fs.IntVar(&opts.FillHorizon, "fill-horizon", 24, "hours ahead the fill rate is predicted")
+
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if *release != "" {
+			opts.Labels["release"] = *release
+		}
+
+		return generate.Rules(os.Stdout, opts)
+	case "dashboard":
+		opts := generate.DashboardOptions{}
+		fs.StringVar(&opts.Title, "title", "LocalSight", "title of the dashboard")
+		fs.StringVar(&opts.UID, "uid", "localsight", "uid of the dashboard")
+		fs.IntVar(&opts.TopK, "topk", 10, "number of series shown in each panel")
+
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		return generate.Dashboard(os.Stdout, opts)
+	default:
+		return fmt.Errorf("unknown generate target: %s", args[0])
+	}
+}
@@ -0,0 +1,316 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// constant values for the alert statuses
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Alert is a firing or resolved alert of a rule on a series.
+type Alert struct {
+	Status   string
+	Rule     *Rule
+	Labels   map[string]string
+	Value    float64
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// state tracks a rule on a single series between evaluations.
+type state struct {
+	node    string
+	labels  map[string]string
+	pending time.Time
+	firing  bool
+	started time.Time
+	// the last notification of the firing alert
+	notified time.Time
+
+	// the previous value, used by growth rules
+	lastValue float64
+	lastTime  time.Time
+}
+
+// point is the evaluated value of a rule on a series.
+type point struct {
+	labels map[string]string
+	value  float64
+}
+
+// Engine evaluates the alerting rules on every snapshot and notifies the webhooks
+// when alerts fire or resolve. It implements the sink interface.
+type Engine struct {
+	cfg      *Config
+	logr     *zap.Logger
+	notifier *notifier
+
+	lock   sync.Mutex
+	states map[string]*state
+}
+
+// NewEngine creates a rule engine for the given rules.
+func NewEngine(logr *zap.Logger, cfg *Config) *Engine {
+	return &Engine{
+		cfg:      cfg,
+		logr:     logr,
+		notifier: newNotifier(cfg.Webhooks),
+		states:   make(map[string]*state),
+	}
+}
+
+// Name returns the name of the sink.
+func (e *Engine) Name() string {
+	return "alerts"
+}
+
+// SetConfig replaces the rules and the webhooks of the engine. The states of the
+// rules that were removed are dropped without notifications.
+func (e *Engine) SetConfig(cfg *Config) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	names := make(map[string]bool, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		names[rule.Name] = true
+	}
+
+	for key := range e.states {
+		if name, _, _ := strings.Cut(key, "\x00"); !names[name] {
+			delete(e.states, key)
+		}
+	}
+
+	e.cfg = cfg
+	e.notifier = newNotifier(cfg.Webhooks)
+}
+
+// Write evaluates the rules on the snapshot and sends the notifications.
+func (e *Engine) Write(ctx context.Context, snapshot *metrics.Snapshot) error {
+	alerts, notifier := e.evaluate(snapshot)
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	for _, alert := range alerts {
+		e.logr.Info(
+			"alert "+alert.Status,
+			zap.String("rule", alert.Rule.Name),
+			zap.Any("labels", alert.Labels),
+			zap.Float64("value", alert.Value),
+		)
+	}
+
+	return notifier.notify(ctx, alerts)
+}
+
+// evaluate updates the rule states with the snapshot and returns the alerts that changed,
+// with the notifier of the rules they were evaluated with.
+func (e *Engine) evaluate(snapshot *metrics.Snapshot) ([]*Alert, *notifier) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	now := snapshot.Timestamp
+	index := indexSamples(snapshot)
+	seen := make(map[string]bool)
+
+	var alerts []*Alert
+	for _, rule := range e.cfg.Rules {
+		for _, p := range rule.points(index) {
+			key := seriesKey(rule.Name, p.labels)
+			seen[key] = true
+
+			st, ok := e.states[key]
+			if !ok {
+				st = &state{node: snapshot.Node, labels: p.labels}
+				e.states[key] = st
+			}
+
+			if alert := e.step(rule, st, p.value, now); alert != nil {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	// a failed fetch has no series, so the alerts are kept until the node is back
+	if !nodeUp(index, snapshot.Node) {
+		return alerts, e.notifier
+	}
+
+	// resolve the alerts of the series that disappeared from the node
+	for key, st := range e.states {
+		if st.node != snapshot.Node || seen[key] {
+			continue
+		}
+
+		if st.firing {
+			alerts = append(alerts, &Alert{
+				Status:   StatusResolved,
+				Rule:     e.rule(key),
+				Labels:   st.labels,
+				StartsAt: st.started,
+				EndsAt:   now,
+			})
+		}
+
+		delete(e.states, key)
+	}
+
+	return alerts, e.notifier
+}
+
+// step moves the state of a series forward and returns an alert when it fires or resolves.
+func (e *Engine) step(rule *Rule, st *state, value float64, now time.Time) *Alert {
+	// growth rules evaluate the change per hour since the previous value
+	if rule.Kind == KindGrowth {
+		previous, last := st.lastValue, st.lastTime
+		st.lastValue, st.lastTime = value, now
+
+		if last.IsZero() || !now.After(last) {
+			return nil
+		}
+
+		value = (value - previous) / now.Sub(last).Hours()
+	}
+
+	switch {
+	case st.firing && rule.recovered(value):
+		st.firing = false
+		st.pending = time.Time{}
+
+		return &Alert{Status: StatusResolved, Rule: rule, Labels: st.labels, Value: value, StartsAt: st.started, EndsAt: now}
+	case st.firing:
+		// send the firing alert again every repeat interval
+		if e.cfg.repeat == 0 || now.Sub(st.notified) < e.cfg.repeat {
+			return nil
+		}
+
+		st.notified = now
+
+		return &Alert{Status: StatusFiring, Rule: rule, Labels: st.labels, Value: value, StartsAt: st.started}
+	case !rule.breached(value):
+		st.pending = time.Time{}
+		return nil
+	}
+
+	// the value is breached, wait for the for duration before firing
+	if st.pending.IsZero() {
+		st.pending = now
+	}
+
+	if now.Sub(st.pending) < rule.duration {
+		return nil
+	}
+
+	st.firing = true
+	st.started = now
+	st.notified = now
+
+	return &Alert{Status: StatusFiring, Rule: rule, Labels: st.labels, Value: value, StartsAt: now}
+}
+
+// rule returns the rule of a state key.
+func (e *Engine) rule(key string) *Rule {
+	name, _, _ := strings.Cut(key, "\x00")
+	for _, rule := range e.cfg.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return &Rule{Name: name}
+}
+
+// sampleIndex holds the samples of a snapshot by definition and label values.
+type sampleIndex map[*metrics.Definition]map[string]metrics.Sample
+
+// indexSamples indexes the samples of a snapshot.
+func indexSamples(snapshot *metrics.Snapshot) sampleIndex {
+	index := make(sampleIndex)
+	for _, sample := range snapshot.Samples {
+		if index[sample.Definition] == nil {
+			index[sample.Definition] = make(map[string]metrics.Sample)
+		}
+
+		index[sample.Definition][strings.Join(sample.LabelValues, "\x00")] = sample
+	}
+
+	return index
+}
+
+// nodeUp returns false if the summary API of the node was down in the snapshot.
+func nodeUp(index sampleIndex, node string) bool {
+	sample, ok := index[metrics.APIStatus][node]
+
+	return !ok || sample.Value != 0
+}
+
+// points returns the evaluated values of the rule for every matching series.
+func (r *Rule) points(index sampleIndex) []point {
+	var points []point
+	nodes := make(map[string]int)
+
+	for key, sample := range index[r.definition] {
+		labels := make(map[string]string, len(sample.LabelValues))
+		for i, name := range r.definition.Labels {
+			labels[name] = sample.LabelValues[i]
+		}
+
+		if !r.matches(labels) {
+			continue
+		}
+
+		value := sample.Value
+		if r.Kind == KindPercent {
+			capacity, ok := index[r.capacity][key]
+			if !ok || capacity.Value == 0 {
+				continue
+			}
+
+			value = 100 * value / capacity.Value
+		}
+
+		// sum the values of a node into a single point
+		if r.Aggregate == "node" {
+			node := labels["exported_node"]
+			if i, ok := nodes[node]; ok {
+				points[i].value += value
+				continue
+			}
+
+			nodes[node] = len(points)
+			labels = map[string]string{"exported_node": node}
+		}
+
+		points = append(points, point{labels: labels, value: value})
+	}
+
+	return points
+}
+
+// matches returns true if the labels match all matchers of the rule.
+func (r *Rule) matches(labels map[string]string) bool {
+	for name, value := range r.Matchers {
+		if labels[name] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// seriesKey returns a unique key of a rule on a series.
+func seriesKey(rule string, labels map[string]string) string {
+	return rule + "\x00" + fmt.Sprint(labels)
+}
@@ -0,0 +1,195 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// start is the time of the first snapshot of the tests.
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestEngine creates an engine with a rule on the used bytes of the pods, which fires
+// above 80 after a minute and resolves below 70.
+func newTestEngine(t *testing.T, repeat string, webhooks ...Webhook) *Engine {
+	t.Helper()
+
+	resolve := 70.0
+	cfg := &Config{
+		Webhooks:       webhooks,
+		RepeatInterval: repeat,
+		Rules: []*Rule{{
+			Name:      "used",
+			Metric:    metrics.EphemeralStorageUsageBytes.FQName(),
+			Op:        ">",
+			Threshold: 80,
+			Resolve:   &resolve,
+			For:       "1m",
+		}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewEngine(zap.NewNop(), cfg)
+}
+
+// snapshot returns a snapshot of the node at the given minute, with the used bytes of
+// pod web, or a failed fetch when the used bytes are negative.
+func snapshot(minute int, used float64) *metrics.Snapshot {
+	s := metrics.NewSnapshot("node")
+	s.Timestamp = start.Add(time.Duration(minute) * time.Minute)
+
+	if used < 0 {
+		s.SetAPIStatus("node", 0)
+		return s
+	}
+
+	s.SetAPIStatus("node", 1)
+	s.SetEphemeralStorageValues("web", "default", "node", used, 100-used, 100)
+
+	return s
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	engine := newTestEngine(t, "")
+
+	tests := []struct {
+		name   string
+		minute int
+		used   float64
+		status string
+	}{
+		{name: "below the threshold", minute: 0, used: 50},
+		{name: "pending", minute: 1, used: 90},
+		{name: "still pending", minute: 1, used: 95},
+		{name: "firing", minute: 2, used: 90, status: StatusFiring},
+		{name: "still firing", minute: 3, used: 75},
+		{name: "failed fetch", minute: 4, used: -1},
+		{name: "firing after the failed fetch", minute: 5, used: 85},
+		{name: "resolved", minute: 6, used: 60, status: StatusResolved},
+		{name: "pending again", minute: 7, used: 90},
+		{name: "pending reset", minute: 8, used: 50},
+		{name: "not firing without a full for duration", minute: 9, used: 90},
+	}
+
+	for _, tt := range tests {
+		alerts, _ := engine.evaluate(snapshot(tt.minute, tt.used))
+
+		switch {
+		case tt.status == "" && len(alerts) != 0:
+			t.Errorf("%s: expected no alerts, got %s", tt.name, alerts[0].Status)
+		case tt.status != "" && len(alerts) != 1:
+			t.Errorf("%s: expected a %s alert, got %d alerts", tt.name, tt.status, len(alerts))
+		case tt.status != "" && alerts[0].Status != tt.status:
+			t.Errorf("%s: expected a %s alert, got %s", tt.name, tt.status, alerts[0].Status)
+		}
+	}
+}
+
+func TestEngineDisappeared(t *testing.T) {
+	engine := newTestEngine(t, "")
+
+	engine.evaluate(snapshot(0, 90))
+	engine.evaluate(snapshot(1, 90))
+
+	// the pod is gone from a successful fetch
+	s := metrics.NewSnapshot("node")
+	s.Timestamp = start.Add(2 * time.Minute)
+	s.SetAPIStatus("node", 1)
+
+	alerts, _ := engine.evaluate(s)
+	if len(alerts) != 1 || alerts[0].Status != StatusResolved {
+		t.Fatalf("expected a resolved alert, got %v", alerts)
+	}
+
+	if len(engine.states) != 0 {
+		t.Errorf("expected no states, got %d", len(engine.states))
+	}
+}
+
+func TestEngineRepeat(t *testing.T) {
+	engine := newTestEngine(t, "10m")
+
+	var firing []int
+	for minute := 0; minute <= 25; minute++ {
+		alerts, _ := engine.evaluate(snapshot(minute, 90))
+		if len(alerts) > 0 {
+			firing = append(firing, minute)
+
+			if !alerts[0].StartsAt.Equal(start.Add(time.Minute)) {
+				t.Errorf("minute %d: expected the start of the first notification, got %s", minute, alerts[0].StartsAt)
+			}
+		}
+	}
+
+	if len(firing) != 3 || firing[0] != 1 || firing[1] != 11 || firing[2] != 21 {
+		t.Errorf("expected notifications at minutes [1 11 21], got %v", firing)
+	}
+}
+
+func TestNotifyRetry(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+
+		var payload genericPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+
+		// the first request fails and the second one is accepted
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	engine := newTestEngine(t, "", Webhook{URL: server.URL, Format: FormatGeneric})
+	engine.notifier.backoff = time.Millisecond
+
+	engine.evaluate(snapshot(0, 90))
+	if err := engine.Write(context.Background(), snapshot(1, 90)); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	// the client errors are not retried
+	client := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer client.Close()
+
+	requests = 0
+	engine.notifier.webhooks = []Webhook{{URL: client.URL, Format: FormatGeneric}}
+
+	if err := engine.Write(context.Background(), snapshot(2, 50)); err == nil {
+		t.Error("expected an error from the webhook")
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
@@ -0,0 +1,209 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// notifyAttempts is the number of tries of a webhook request, with a doubling backoff
+// between them.
+const notifyAttempts = 3
+
+// notifier sends the alerts to the webhooks.
+type notifier struct {
+	webhooks []Webhook
+	http     *http.Client
+	backoff  time.Duration
+}
+
+// statusError is a webhook response with an unexpected status code.
+type statusError struct {
+	code int
+	body []byte
+}
+
+// Error implements the error interface.
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.code, e.body)
+}
+
+// alertmanagerAlert is an alert in the Alertmanager API and webhook format.
+type alertmanagerAlert struct {
+	Status       string            `json:"status,omitempty"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt,omitzero"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// genericPayload is the body of the generic webhooks, compatible with the Alertmanager webhook format.
+type genericPayload struct {
+	Version  string              `json:"version"`
+	Receiver string              `json:"receiver"`
+	Status   string              `json:"status"`
+	Alerts   []alertmanagerAlert `json:"alerts"`
+}
+
+// slackPayload is the body of the Slack incoming webhooks.
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// newNotifier creates a notifier for the webhooks.
+func newNotifier(webhooks []Webhook) *notifier {
+	return &notifier{
+		webhooks: webhooks,
+		http:     &http.Client{},
+		backoff:  time.Second,
+	}
+}
+
+// notify sends the alerts to every webhook and returns the joined errors.
+func (n *notifier) notify(ctx context.Context, alerts []*Alert) error {
+	var errs []error
+	for _, webhook := range n.webhooks {
+		var body any
+		switch webhook.Format {
+		case FormatAlertmanager:
+			body = toAlertmanager(alerts)
+		case FormatSlack:
+			body = toSlack(alerts)
+		default:
+			body = toGeneric(alerts)
+		}
+
+		if err := n.send(ctx, webhook.URL, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", webhook.URL, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send posts the body to the URL and retries the failed requests with a backoff, until
+// the attempts run out or the context is done.
+func (n *notifier) send(ctx context.Context, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		err := n.post(ctx, url, data)
+		if err == nil || attempt == notifyAttempts || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// retryable returns true if the request may succeed when sent again. The client errors
+// of the webhook, other than rate limits, are not retried.
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code == http.StatusTooManyRequests || status.code >= 500
+	}
+
+	return true
+}
+
+// post sends the JSON data to the URL.
+func (n *notifier) post(ctx context.Context, url string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return &statusError{code: resp.StatusCode, body: bytes.TrimSpace(msg)}
+	}
+
+	return nil
+}
+
+// toAlertmanager converts the alerts into the Alertmanager API format.
+func toAlertmanager(alerts []*Alert) []alertmanagerAlert {
+	list := make([]alertmanagerAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		labels := maps.Clone(alert.Labels)
+		labels["alertname"] = alert.Rule.Name
+		labels["severity"] = alert.Rule.Severity
+
+		list = append(list, alertmanagerAlert{
+			Status: alert.Status,
+			Labels: labels,
+			Annotations: map[string]string{
+				"summary": alert.Rule.Summary,
+				"value":   fmt.Sprintf("%g", alert.Value),
+			},
+			StartsAt:     alert.StartsAt,
+			EndsAt:       alert.EndsAt,
+			GeneratorURL: "localsight://" + alert.Rule.Name,
+		})
+	}
+
+	return list
+}
+
+// toGeneric converts the alerts into the generic webhook format.
+func toGeneric(alerts []*Alert) genericPayload {
+	status := StatusResolved
+	for _, alert := range alerts {
+		if alert.Status == StatusFiring {
+			status = StatusFiring
+		}
+	}
+
+	return genericPayload{
+		Version:  "4",
+		Receiver: "localsight",
+		Status:   status,
+		Alerts:   toAlertmanager(alerts),
+	}
+}
+
+// toSlack converts the alerts into a Slack message.
+func toSlack(alerts []*Alert) slackPayload {
+	var b strings.Builder
+	for _, alert := range alerts {
+		fmt.Fprintf(&b, "[%s] *%s* (%s) value=%g", strings.ToUpper(alert.Status), alert.Rule.Name, alert.Rule.Severity, alert.Value)
+		for _, name := range []string{"exported_node", "exported_namespace", "exported_pod", "exported_container", "exported_volume"} {
+			if value := alert.Labels[name]; value != "" {
+				fmt.Fprintf(&b, " %s=%s", strings.TrimPrefix(name, "exported_"), value)
+			}
+		}
+
+		if alert.Rule.Summary != "" {
+			fmt.Fprintf(&b, "\n%s", alert.Rule.Summary)
+		}
+		b.WriteByte('\n')
+	}
+
+	return slackPayload{Text: b.String()}
+}
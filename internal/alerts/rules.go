@@ -0,0 +1,236 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// constant values for the rule kinds
+const (
+	KindValue   = "value"
+	KindPercent = "percent"
+	KindGrowth  = "growth"
+)
+
+// constant values for the webhook formats
+const (
+	FormatGeneric      = "generic"
+	FormatSlack        = "slack"
+	FormatAlertmanager = "alertmanager"
+)
+
+// defaultRepeatInterval is the interval of the notifications of a firing alert, when
+// the rules file does not set one.
+const defaultRepeatInterval = 4 * time.Hour
+
+// Config is the content of the alerting rules file. Firing alerts are sent again every
+// repeat interval, until they resolve; a zero interval sends them once.
+type Config struct {
+	Webhooks       []Webhook `json:"webhooks"`
+	RepeatInterval string    `json:"repeat_interval,omitempty"`
+	Rules          []*Rule   `json:"rules"`
+
+	repeat time.Duration
+}
+
+// Webhook is a notification endpoint.
+type Webhook struct {
+	URL    string `json:"url"`
+	Format string `json:"format"`
+}
+
+// Rule is a threshold on a metric, evaluated for every series of the metric after each collection.
+//
+// The kind selects the evaluated value: the sample value (value), the sample value as a
+// percentage of its capacity series (percent), or the growth of the value per hour (growth).
+// An alert fires when the value has crossed the threshold for the whole "for" duration,
+// and resolves once the value crosses back the resolve threshold, which defaults to the threshold.
+type Rule struct {
+	Name      string            `json:"name"`
+	Metric    string            `json:"metric"`
+	Kind      string            `json:"kind"`
+	Op        string            `json:"op"`
+	Threshold float64           `json:"threshold"`
+	Resolve   *float64          `json:"resolve,omitempty"`
+	For       string            `json:"for,omitempty"`
+	Aggregate string            `json:"aggregate,omitempty"`
+	Matchers  map[string]string `json:"matchers,omitempty"`
+	Severity  string            `json:"severity,omitempty"`
+	Summary   string            `json:"summary,omitempty"`
+
+	definition *metrics.Definition
+	capacity   *metrics.Definition
+	duration   time.Duration
+}
+
+// Load reads and validates the rules file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode rules file: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the webhooks and the rules, and resolves the rule metrics.
+func (c *Config) Validate() error {
+	c.repeat = defaultRepeatInterval
+	if c.RepeatInterval != "" {
+		d, err := time.ParseDuration(c.RepeatInterval)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid repeat interval %q", c.RepeatInterval)
+		}
+
+		c.repeat = d
+	}
+
+	for i, webhook := range c.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("webhook %d: url is required", i)
+		}
+
+		switch webhook.Format {
+		case "":
+			c.Webhooks[i].Format = FormatGeneric
+		case FormatGeneric, FormatSlack, FormatAlertmanager:
+		default:
+			return fmt.Errorf("webhook %d: unknown format %q", i, webhook.Format)
+		}
+	}
+
+	names := make(map[string]bool, len(c.Rules))
+	for _, rule := range c.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+
+		if names[rule.Name] {
+			return fmt.Errorf("rule %q: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	return nil
+}
+
+// validate checks the rule fields and sets the defaults.
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	r.definition = findDefinition(r.Metric)
+	if r.definition == nil {
+		return fmt.Errorf("unknown metric %q", r.Metric)
+	}
+
+	switch r.Kind {
+	case "":
+		r.Kind = KindValue
+	case KindValue, KindGrowth:
+	case KindPercent:
+		r.capacity = capacityOf(r.definition)
+		if r.capacity == nil {
+			return fmt.Errorf("metric %q has no capacity series for a percent rule", r.Metric)
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+
+	if r.Op != ">" && r.Op != "<" {
+		return fmt.Errorf("op must be > or <, got %q", r.Op)
+	}
+
+	if r.Aggregate != "" && r.Aggregate != "node" {
+		return fmt.Errorf("aggregate must be empty or node, got %q", r.Aggregate)
+	}
+
+	if r.Aggregate == "node" && r.Kind != KindValue {
+		return fmt.Errorf("node aggregation only supports value rules")
+	}
+
+	if r.For != "" {
+		d, err := time.ParseDuration(r.For)
+		if err != nil {
+			return fmt.Errorf("invalid for duration: %w", err)
+		}
+
+		r.duration = d
+	}
+
+	if r.Resolve == nil {
+		r.Resolve = &r.Threshold
+	} else if (r.Op == ">" && *r.Resolve > r.Threshold) || (r.Op == "<" && *r.Resolve < r.Threshold) {
+		return fmt.Errorf("resolve threshold must be on the other side of the threshold")
+	}
+
+	if r.Severity == "" {
+		r.Severity = "warning"
+	}
+
+	return nil
+}
+
+// breached returns true if the value crossed the threshold.
+func (r *Rule) breached(value float64) bool {
+	if r.Op == ">" {
+		return value > r.Threshold
+	}
+
+	return value < r.Threshold
+}
+
+// recovered returns true if the value crossed back the resolve threshold.
+func (r *Rule) recovered(value float64) bool {
+	if r.Op == ">" {
+		return value < *r.Resolve
+	}
+
+	return value > *r.Resolve
+}
+
+// findDefinition returns the metric definition with the given fully qualified name.
+func findDefinition(name string) *metrics.Definition {
+	for _, def := range metrics.Definitions {
+		if def.FQName() == name {
+			return def
+		}
+	}
+
+	return nil
+}
+
+// capacityOf returns the capacity series of the same subsystem, used by percent rules.
+func capacityOf(def *metrics.Definition) *metrics.Definition {
+	var name string
+	switch def.Name {
+	case "used_bytes", "usage_bytes", "available_bytes":
+		name = "capacity_bytes"
+	case "inodes_used", "inodes_free":
+		name = "inodes_total"
+	default:
+		return nil
+	}
+
+	for _, d := range metrics.Definitions {
+		if d.Subsystem == def.Subsystem && d.Name == name {
+			return d
+		}
+	}
+
+	return nil
+}
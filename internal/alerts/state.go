@@ -0,0 +1,80 @@
+package alerts
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// savedState is the checkpointed state of a rule on a series.
+type savedState struct {
+	Key       string            `json:"key"`
+	Node      string            `json:"node"`
+	Labels    map[string]string `json:"labels"`
+	Pending   time.Time         `json:"pending,omitzero"`
+	Firing    bool              `json:"firing,omitempty"`
+	Started   time.Time         `json:"started,omitzero"`
+	Notified  time.Time         `json:"notified,omitzero"`
+	LastValue float64           `json:"last_value,omitempty"`
+	LastTime  time.Time         `json:"last_time,omitzero"`
+}
+
+// MarshalState returns the rule states for the checkpoint, so the pending for durations,
+// the firing alerts and the growth rates survive a restart.
+func (e *Engine) MarshalState() ([]byte, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	list := make([]savedState, 0, len(e.states))
+	for key, st := range e.states {
+		list = append(list, savedState{
+			Key:       key,
+			Node:      st.node,
+			Labels:    st.labels,
+			Pending:   st.pending,
+			Firing:    st.firing,
+			Started:   st.started,
+			Notified:  st.notified,
+			LastValue: st.lastValue,
+			LastTime:  st.lastTime,
+		})
+	}
+
+	return json.Marshal(list)
+}
+
+// RestoreState loads the rule states of a checkpoint, dropping the states of the rules
+// that no longer exist.
+func (e *Engine) RestoreState(data []byte) error {
+	var list []savedState
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	names := make(map[string]bool, len(e.cfg.Rules))
+	for _, rule := range e.cfg.Rules {
+		names[rule.Name] = true
+	}
+
+	for _, saved := range list {
+		if name, _, _ := strings.Cut(saved.Key, "\x00"); !names[name] {
+			continue
+		}
+
+		e.states[saved.Key] = &state{
+			node:      saved.Node,
+			labels:    saved.Labels,
+			pending:   saved.Pending,
+			firing:    saved.Firing,
+			started:   saved.Started,
+			notified:  saved.Notified,
+			lastValue: saved.LastValue,
+			lastTime:  saved.LastTime,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package anomaly
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// constant values of the deviation floors, in rate per second
+const (
+	bytesFloor  = 64 << 10
+	inodesFloor = 10
+)
+
+// staleAfter is how long the state of a pod is kept after its last observation.
+const staleAfter = time.Hour
+
+// Config holds the options of the detector.
+type Config struct {
+	// Alpha is the weight of the latest rate in the moving average, between 0 and 1.
+	Alpha float64
+	// Threshold is the score above which a growth is reported as an anomaly.
+	Threshold float64
+	// Warmup is the number of rates observed before the scores are computed.
+	Warmup int
+}
+
+// Scores are the anomaly scores of a pod.
+type Scores struct {
+	Used   float64
+	Inodes float64
+}
+
+// series is the state of a pod between observations.
+type series struct {
+	LastUsed   float64   `json:"last_used"`
+	LastInodes float64   `json:"last_inodes"`
+	LastTime   time.Time `json:"last_time"`
+	Used       ewma      `json:"used"`
+	Inodes     ewma      `json:"inodes"`
+}
+
+// Detector scores the growth of the pod ephemeral storage against its moving average
+// and deviation, so steady growth scores low and sudden jumps score high.
+type Detector struct {
+	cfg  Config
+	logr *zap.Logger
+
+	lock   sync.Mutex
+	series map[string]*series
+	pruned time.Time
+}
+
+// NewDetector creates an anomaly detector.
+func NewDetector(logr *zap.Logger, cfg Config) *Detector {
+	return &Detector{
+		cfg:    cfg,
+		logr:   logr,
+		series: make(map[string]*series),
+	}
+}
+
+// Observe adds the usage of a pod and returns its scores. The scores are zero until the
+// warmup is done. A score above the threshold is logged as an anomaly.
+func (d *Detector) Observe(pod, namespace, node string, at time.Time, used, inodes float64) Scores {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.prune(at)
+
+	key := namespace + "/" + pod + "/" + node
+	s, ok := d.series[key]
+	if !ok || !at.After(s.LastTime) {
+		if !ok {
+			d.series[key] = &series{LastUsed: used, LastInodes: inodes, LastTime: at}
+		}
+
+		return Scores{}
+	}
+
+	// the rates per second since the previous observation
+	seconds := at.Sub(s.LastTime).Seconds()
+	usedRate := (used - s.LastUsed) / seconds
+	inodesRate := (inodes - s.LastInodes) / seconds
+
+	var scores Scores
+	if s.Used.Count >= d.cfg.Warmup {
+		scores.Used = s.Used.score(usedRate, bytesFloor)
+		scores.Inodes = s.Inodes.score(inodesRate, inodesFloor)
+	}
+
+	if scores.Used >= d.cfg.Threshold || scores.Inodes >= d.cfg.Threshold {
+		d.logr.Warn(
+			"storage growth anomaly",
+			zap.String("pod", pod),
+			zap.String("namespace", namespace),
+			zap.String("node", node),
+			zap.Float64("used-bytes-per-second", usedRate),
+			zap.Float64("expected-bytes-per-second", s.Used.Mean),
+			zap.Float64("used-score", scores.Used),
+			zap.Float64("inodes-per-second", inodesRate),
+			zap.Float64("expected-inodes-per-second", s.Inodes.Mean),
+			zap.Float64("inodes-score", scores.Inodes),
+		)
+	}
+
+	s.Used.update(usedRate, d.cfg.Alpha)
+	s.Inodes.update(inodesRate, d.cfg.Alpha)
+	s.LastUsed, s.LastInodes, s.LastTime = used, inodes, at
+
+	return scores
+}
+
+// prune drops the pods that were not observed recently, at most once a minute.
+func (d *Detector) prune(now time.Time) {
+	if now.Sub(d.pruned) < time.Minute {
+		return
+	}
+
+	for key, s := range d.series {
+		if now.Sub(s.LastTime) > staleAfter {
+			delete(d.series, key)
+		}
+	}
+
+	d.pruned = now
+}
@@ -0,0 +1,34 @@
+package anomaly
+
+import "math"
+
+// ewma tracks the exponentially weighted moving average and variance of a rate.
+type ewma struct {
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+	Count    int     `json:"count"`
+}
+
+// score returns the number of deviations of the value from the average. The deviation
+// is at least the floor, so a series that never changed does not score every change
+// as an infinite anomaly.
+func (e *ewma) score(value, floor float64) float64 {
+	return (value - e.Mean) / max(math.Sqrt(e.Variance), floor)
+}
+
+// update adds the value to the average with the weight alpha.
+func (e *ewma) update(value, alpha float64) {
+	if e.Count == 0 {
+		e.Mean = value
+		e.Count++
+
+		return
+	}
+
+	diff := value - e.Mean
+	incr := alpha * diff
+
+	e.Mean += incr
+	e.Variance = (1 - alpha) * (e.Variance + diff*incr)
+	e.Count++
+}
@@ -0,0 +1,65 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEWMAUpdate(t *testing.T) {
+	tests := []struct {
+		value    float64
+		mean     float64
+		variance float64
+	}{
+		// the first value starts the average without a variance
+		{value: 10, mean: 10, variance: 0},
+		{value: 20, mean: 15, variance: 25},
+		{value: 20, mean: 17.5, variance: 18.75},
+		{value: 17.5, mean: 17.5, variance: 9.375},
+	}
+
+	var e ewma
+	for i, tt := range tests {
+		e.update(tt.value, 0.5)
+
+		if e.Count != i+1 {
+			t.Errorf("update %d: expected count %d, got %d", i, i+1, e.Count)
+		}
+
+		if math.Abs(e.Mean-tt.mean) > 1e-9 || math.Abs(e.Variance-tt.variance) > 1e-9 {
+			t.Errorf("update %d: expected mean %g and variance %g, got %g and %g", i, tt.mean, tt.variance, e.Mean, e.Variance)
+		}
+	}
+}
+
+func TestEWMAScore(t *testing.T) {
+	e := ewma{Mean: 17.5, Variance: 18.75, Count: 3}
+
+	tests := []struct {
+		name  string
+		value float64
+		floor float64
+		want  float64
+	}{
+		{name: "at the average", value: 17.5, floor: 1, want: 0},
+		{name: "above the average", value: 27.5, floor: 1, want: 10 / math.Sqrt(18.75)},
+		{name: "below the average", value: 7.5, floor: 1, want: -10 / math.Sqrt(18.75)},
+		{name: "deviation below the floor", value: 27.5, floor: 10, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := e.score(tt.value, tt.floor); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %g, got %g", tt.name, tt.want, got)
+		}
+	}
+
+	// a series that never changed scores a change against the floor
+	var steady ewma
+	for range 5 {
+		steady.update(100, 0.1)
+	}
+
+	if got := steady.score(150, 10); got != 5 {
+		t.Errorf("steady series: expected 5, got %g", got)
+	}
+}
@@ -0,0 +1,34 @@
+package anomaly
+
+import "encoding/json"
+
+// Name returns the name of the detector in the checkpoint.
+func (d *Detector) Name() string {
+	return "anomaly"
+}
+
+// MarshalState returns the moving averages of every pod for the checkpoint.
+func (d *Detector) MarshalState() ([]byte, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return json.Marshal(d.series)
+}
+
+// RestoreState loads the moving averages of a checkpoint, so the warmup is not
+// repeated after a restart.
+func (d *Detector) RestoreState(data []byte) error {
+	list := make(map[string]*series)
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for key, s := range list {
+		d.series[key] = s
+	}
+
+	return nil
+}
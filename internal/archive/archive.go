@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Record is a single summary response of the archive, stored as it was received.
+type Record struct {
+	Timestamp time.Time       `json:"t"`
+	Endpoint  string          `json:"endpoint,omitempty"`
+	Summary   json.RawMessage `json:"summary"`
+}
+
+// Writer appends records to a gzipped JSON lines archive. Every record is flushed, so
+// an archive of an interrupted recording stays readable up to its last record.
+type Writer struct {
+	file *os.File
+	zw   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// Create creates the archive file, truncating an existing one.
+func Create(path string) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	zw := gzip.NewWriter(file)
+
+	return &Writer{
+		file: file,
+		zw:   zw,
+		enc:  json.NewEncoder(zw),
+	}, nil
+}
+
+// Write appends a record to the archive.
+func (w *Writer) Write(record *Record) error {
+	if err := w.enc.Encode(record); err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	if err := w.zw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush archive: %w", err)
+	}
+
+	return nil
+}
+
+// Close completes the gzip stream and closes the archive file.
+func (w *Writer) Close() error {
+	if err := w.zw.Close(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	return w.file.Close()
+}
+
+// Reader reads the records of an archive in order.
+type Reader struct {
+	file *os.File
+	zr   *gzip.Reader
+	dec  *json.Decoder
+}
+
+// Open opens the archive file for reading.
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+
+	return &Reader{
+		file: file,
+		zr:   zr,
+		dec:  json.NewDecoder(zr),
+	}, nil
+}
+
+// Next returns the next record of the archive, and io.EOF after the last one. The
+// truncated end of an interrupted recording is treated as the end of the archive.
+func (r *Reader) Next() (*Record, error) {
+	var record Record
+	if err := r.dec.Decode(&record); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("failed to decode record: %w", err)
+	}
+
+	return &record, nil
+}
+
+// Close closes the archive file.
+func (r *Reader) Close() error {
+	r.zr.Close()
+	return r.file.Close()
+}
@@ -0,0 +1,191 @@
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// version is the format version of the checkpoint file. A file of another version is
+// ignored on load, so an upgrade starts with an empty state instead of a wrong one.
+const version = 1
+
+// Component is a part of the exporter whose state is checkpointed.
+type Component interface {
+	Name() string
+	MarshalState() ([]byte, error)
+	RestoreState(data []byte) error
+}
+
+// file is the content of the checkpoint file, stored as gzipped JSON.
+type file struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	// Checksum is the CRC32 of each component state, to detect a corrupted state.
+	Checksum   map[string]uint32          `json:"checksum"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// Checkpointer periodically saves the state of the components to a file, and restores
+// it on start, so the in-process history survives restarts and upgrades.
+type Checkpointer struct {
+	Path       string
+	Interval   time.Duration
+	Components []Component
+	Logr       *zap.Logger
+}
+
+// Restore loads the checkpoint file and restores the state of every component. A missing,
+// corrupted or outdated file or component state is logged and skipped.
+func (c *Checkpointer) Restore() {
+	f, err := c.load()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			c.Logr.Info("no checkpoint to restore", zap.String("path", c.Path))
+		} else {
+			c.Logr.Warn("ignoring unreadable checkpoint", zap.String("path", c.Path), zap.Error(err))
+		}
+
+		return
+	}
+
+	for _, component := range c.Components {
+		data, ok := f.Components[component.Name()]
+		if !ok {
+			continue
+		}
+
+		if crc32.ChecksumIEEE(data) != f.Checksum[component.Name()] {
+			c.Logr.Warn("ignoring corrupted checkpoint state", zap.String("component", component.Name()))
+			continue
+		}
+
+		if err := component.RestoreState(data); err != nil {
+			c.Logr.Warn("failed to restore checkpoint state", zap.String("component", component.Name()), zap.Error(err))
+			continue
+		}
+
+		c.Logr.Info(
+			"restored checkpoint state",
+			zap.String("component", component.Name()),
+			zap.Time("saved-at", f.SavedAt),
+		)
+	}
+}
+
+// Start saves the checkpoint on every interval, until the context is done.
+func (c *Checkpointer) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.Save(); err != nil {
+			c.Logr.Error("failed to save checkpoint", zap.Error(err))
+		}
+	}
+}
+
+// Save writes the state of every component to the checkpoint file atomically, by
+// writing a temporary file in the same directory and renaming it.
+func (c *Checkpointer) Save() error {
+	f := file{
+		Version:    version,
+		SavedAt:    time.Now(),
+		Checksum:   make(map[string]uint32, len(c.Components)),
+		Components: make(map[string]json.RawMessage, len(c.Components)),
+	}
+
+	for _, component := range c.Components {
+		data, err := component.MarshalState()
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s state: %w", component.Name(), err)
+		}
+
+		f.Components[component.Name()] = data
+		f.Checksum[component.Name()] = crc32.ChecksumIEEE(data)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(f); err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress checkpoint: %w", err)
+	}
+
+	return writeFile(c.Path, buf.Bytes())
+}
+
+// load reads and decodes the checkpoint file.
+func (c *Checkpointer) load() (*file, error) {
+	raw, err := os.ReadFile(c.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress checkpoint: %w", err)
+	}
+
+	var f file
+	if err := json.NewDecoder(zr).Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+
+	if f.Version != version {
+		return nil, fmt.Errorf("unsupported checkpoint version %d, expected %d", f.Version, version)
+	}
+
+	return &f, nil
+}
+
+// writeFile writes the data to a temporary file, syncs it and renames it to the path.
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync checkpoint: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename checkpoint: %w", err)
+	}
+
+	return nil
+}
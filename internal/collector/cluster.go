@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/anomaly"
+	"github.com/amirhnajafiz/localsight/internal/kube"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+	"github.com/amirhnajafiz/localsight/internal/sharding"
+	"github.com/amirhnajafiz/localsight/internal/sinks"
+	"github.com/amirhnajafiz/localsight/internal/sources"
+
+	"go.uber.org/zap"
+)
+
+// ClusterCollector collects storage usage metrics of every node in the cluster
+// through the API server node proxy, using a bounded pool of workers.
+type ClusterCollector struct {
+	Client   *kube.Client
+	Registry *sharding.Registry
+	Workers  int
+
+	Logr     *zap.Logger
+	Metrics  *metrics.Metrics
+	Sinks    *sinks.FanOut
+	Options  *Options
+	Detector *anomaly.Detector
+
+	collectors map[string]*Collector
+}
+
+// Start initiates the process of listing the cluster nodes and collecting
+// their summaries on every interval, until the context is done.
+func (c *ClusterCollector) Start(ctx context.Context) error {
+	c.Logr.Info(
+		"starting cluster summary collector",
+		zap.String("api-server", c.Client.Host()),
+		zap.Int("workers", c.Workers),
+		zap.Duration("interval", c.Options.Get().Interval),
+	)
+
+	c.collectors = make(map[string]*Collector)
+	if c.Registry != nil {
+		c.Registry.Start(ctx)
+
+		// leave the members when the collector stops
+		defer func() {
+			if err := c.Registry.Leave(); err != nil {
+				c.Logr.Error("failed to leave sharding members", zap.Error(err))
+			}
+		}()
+	}
+
+	for {
+		// wait for the specified interval before fetching metrics
+		if !wait(ctx, c.Options.Get().Interval) {
+			return nil
+		}
+
+		// list the nodes of the cluster
+		nodes, err := c.Client.ListNodes()
+		if err != nil {
+			c.Logr.Error("failed to list nodes", zap.Error(err))
+			continue
+		}
+
+		// keep the nodes of this replica when sharding is enabled
+		total := len(nodes)
+		if c.Registry != nil {
+			nodes = c.Registry.Filter(nodes)
+		}
+
+		start := time.Now()
+		c.collect(nodes)
+
+		c.Logr.Info(
+			"successfully collected cluster storage usage metrics",
+			zap.Int("nodes", len(nodes)),
+			zap.Int("total", total),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
+
+// collect fetches the summaries of the given nodes using the worker pool.
+func (c *ClusterCollector) collect(nodes []string) {
+	jobs := make(chan *Collector)
+
+	var wg sync.WaitGroup
+	for range max(c.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for col := range jobs {
+				col.collect()
+			}
+		}()
+	}
+
+	for _, col := range c.nodeCollectors(nodes) {
+		jobs <- col
+	}
+
+	close(jobs)
+	wg.Wait()
+}
+
+// nodeCollectors returns a collector for each node, creating the missing ones and
+// dropping the ones of nodes that left the cluster.
+func (c *ClusterCollector) nodeCollectors(nodes []string) []*Collector {
+	list := make([]*Collector, 0, len(nodes))
+	current := make(map[string]*Collector, len(nodes))
+
+	for _, node := range nodes {
+		col, ok := c.collectors[node]
+		if !ok {
+			col = &Collector{
+				NodeName: node,
+				Source:   sources.NewProxy(c.Client, node),
+				Logr:     c.Logr,
+				Sinks:    c.Sinks,
+				Options:  c.Options,
+				Detector: c.Detector,
+			}
+		}
+
+		current[node] = col
+		list = append(list, col)
+	}
+
+	// drop the metrics of the nodes that are no longer collected by this instance
+	for node := range c.collectors {
+		if _, ok := current[node]; !ok {
+			c.Metrics.Remove(node)
+		}
+	}
+
+	c.collectors = current
+
+	return list
+}
@@ -1,86 +1,160 @@
 package collector
 
 import (
-	"fmt"
+	"context"
 	"time"
 
+	"github.com/amirhnajafiz/localsight/internal/anomaly"
 	"github.com/amirhnajafiz/localsight/internal/metrics"
-	"github.com/amirhnajafiz/localsight/pkg/fetch"
+	"github.com/amirhnajafiz/localsight/internal/sinks"
+	"github.com/amirhnajafiz/localsight/internal/sources"
 	"github.com/amirhnajafiz/localsight/pkg/types"
 
 	"go.uber.org/zap"
 )
 
-// Collector is responsible for collecting storage usage metrics from the kubelet summary endpoint
+// Collector is responsible for collecting storage usage metrics from a summary source
 // and updating the provided metrics instance with the collected data.
 type Collector struct {
 	NodeName string
-	CertFile string
-	KeyFile  string
-	EndPoint string
+	Source   sources.Source
 
 	Logr     *zap.Logger
-	Metrics  *metrics.Metrics
-	Interval time.Duration
+	Sinks    *sinks.FanOut
+	Options  *Options
+	Detector *anomaly.Detector
 }
 
-// Start initiates the process of fetching storage usage metrics from the kubelet summary endpoint
-// and updates the provided metrics instance with the data.
-func (c *Collector) Start() error {
-	// build the HTTP request to the kubelet summary endpoint
-	req, err := buildHTTPRequest(c.EndPoint)
+// Start initiates the process of fetching storage usage metrics from the summary source
+// and updates the provided metrics instance with the data, until the context is done.
+func (c *Collector) Start(ctx context.Context) error {
+	c.Logr.Info(
+		"starting summary collector",
+		zap.String("endpoint", c.Source.Endpoint()),
+		zap.Duration("interval", c.Options.Get().Interval),
+	)
+
+	for {
+		// wait for the specified interval before fetching metrics
+		if !wait(ctx, c.Options.Get().Interval) {
+			return nil
+		}
+
+		c.collect()
+	}
+}
+
+// wait sleeps for the duration and returns false if the context is done first.
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// collect fetches a single summary from the source, builds a snapshot of the
+// cycle and publishes it to the metrics.
+func (c *Collector) collect() {
+	c.Logr.Debug("fetching summary for storage usage metrics", zap.String("node", c.NodeName))
+
+	snapshot, meta, err := c.Snapshot()
+
+	// write the complete snapshot to the output sinks at once
+	c.Sinks.Write(snapshot)
+
 	if err != nil {
-		return fmt.Errorf("failed to build HTTP request: %w", err)
+		c.Logr.Error("failed to get summary", zap.String("node", c.NodeName), zap.Error(err))
+		return
+	}
+
+	if snapshot.Duplicates > 0 {
+		c.Logr.Warn(
+			"summary has duplicated series, the last values are kept",
+			zap.String("node", c.NodeName),
+			zap.Int("duplicates", snapshot.Duplicates),
+		)
 	}
 
 	c.Logr.Info(
-		"starting kubelet summary collector",
-		zap.String("endpoint", req.URL.String()),
-		zap.Duration("interval", c.Interval),
+		"successfully set storage usage metrics",
+		zap.String("node", c.NodeName),
+		zap.String("source", meta.Source),
 	)
+}
 
-	for {
-		// wait for the specified interval before fetching metrics
-		time.Sleep(c.Interval)
-		c.Logr.Debug("fetching kubelet summary for storage usage metrics")
+// Snapshot fetches a single summary from the source and builds its snapshot. When the
+// fetch fails, the snapshot holds the failed API status and the error is returned.
+func (c *Collector) Snapshot() (*metrics.Snapshot, *sources.Metadata, error) {
+	snapshot := metrics.NewSnapshot(c.NodeName)
+	settings := c.Options.Get()
 
-		// perform the HTTP GET request
-		start := time.Now()
-		resp, err := fetch.GET(req, c.CertFile, c.KeyFile)
-		if err != nil {
-			c.Metrics.SetAPIStatus(c.NodeName, 0)
-			c.Metrics.SetAPIValues(c.NodeName, 0)
+	// process the pods of the collected namespaces into the snapshot as they are decoded
+	meta, err := c.fetch(snapshot, func(node *types.NodeSummary, pod *types.PodSummary) {
+		if !settings.Collects(pod.PodRef.Namespace) {
+			return
+		}
 
-			c.Logr.Error("failed to fetch kubelet summary", zap.Error(err))
-			continue
+		setPodStorageUsage(snapshot, *pod, node.NodeName)
+		if c.Detector != nil {
+			setPodAnomalyScores(snapshot, c.Detector, *pod, node.NodeName)
 		}
+		setVolumeStorageUsage(snapshot, *pod, node.NodeName)
+		setContainerStorageUsage(snapshot, *pod, node.NodeName)
+	})
+	if err != nil {
+		// drop the pods of a summary that failed halfway
+		snapshot = metrics.NewSnapshot(c.NodeName)
+		snapshot.SetAPIStatus(c.NodeName, 0)
+		snapshot.SetAPIValues(c.NodeName, 0)
 
-		// update API metrics
-		c.Metrics.SetAPIStatus(c.NodeName, 1)
-		c.Metrics.SetAPIValues(c.NodeName, time.Since(start).Seconds())
+		return snapshot, nil, err
+	}
 
-		// decode the JSON response into a summary structure
-		var summary types.Summary
-		if err := fetch.JSON(resp, &summary); err != nil {
-			c.Logr.Error("failed to decode kubelet summary JSON", zap.Error(err))
-			continue
-		}
+	// update API metrics
+	snapshot.SetAPIStatus(c.NodeName, 1)
+	snapshot.SetAPIValues(c.NodeName, meta.Latency.Seconds())
+
+	return snapshot, meta, nil
+}
 
-		// process the summary data and update the metrics
-		for _, pod := range summary.Pods {
-			c.setPodStorageUsage(pod, summary.Node.NodeName)
-			c.setVolumeStorageUsage(pod, summary.Node.NodeName)
-			c.setContainerStorageUsage(pod, summary.Node.NodeName)
+// fetch fetches a summary from the source and calls the function with every pod. The
+// summary is decoded pod by pod when the source supports it, to keep the memory of a
+// node with many pods low. The snapshot takes the fetch time of the source, which is
+// the recorded time of a replayed summary.
+func (c *Collector) fetch(snapshot *metrics.Snapshot, fn func(node *types.NodeSummary, pod *types.PodSummary)) (*sources.Metadata, error) {
+	if stream, ok := c.Source.(sources.StreamSource); ok {
+		meta, err := stream.Stream(fn)
+		if err != nil {
+			return nil, err
 		}
 
-		c.Logr.Info("successfully set storage usage metrics", zap.String("node", c.NodeName))
+		snapshot.Timestamp = meta.FetchedAt
+
+		return meta, nil
+	}
+
+	summary, meta, err := c.Source.Fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Timestamp = meta.FetchedAt
+	for i := range summary.Pods {
+		fn(&summary.Node, &summary.Pods[i])
 	}
+
+	return meta, nil
 }
 
-// setPodStorageUsage sets the ephemeral storage usage for a pod in the provided metrics instance.
-func (c *Collector) setPodStorageUsage(pod types.PodSummary, nodeName string) {
+// setPodStorageUsage sets the ephemeral storage usage for a pod in the snapshot.
+func setPodStorageUsage(snapshot *metrics.Snapshot, pod types.PodSummary, nodeName string) {
 	// set the ephemeral storage usage for the pod
-	c.Metrics.SetEphemeralStorageValues(
+	snapshot.SetEphemeralStorageValues(
 		pod.PodRef.Name,
 		pod.PodRef.Namespace,
 		nodeName,
@@ -90,7 +164,7 @@ func (c *Collector) setPodStorageUsage(pod types.PodSummary, nodeName string) {
 	)
 
 	// set the ephemeral storage inodes for the pod
-	c.Metrics.SetEphemeralStorageInodes(
+	snapshot.SetEphemeralStorageInodes(
 		pod.PodRef.Name,
 		pod.PodRef.Namespace,
 		nodeName,
@@ -98,12 +172,41 @@ func (c *Collector) setPodStorageUsage(pod types.PodSummary, nodeName string) {
 		float64(pod.EphemeralStorage.InodesFree),
 		float64(pod.EphemeralStorage.Inodes),
 	)
+
+	// set the small-file heuristics of the pod
+	snapshot.SetEphemeralStorageInodeUsage(
+		pod.PodRef.Name,
+		pod.PodRef.Namespace,
+		nodeName,
+		bytesPerInode(pod.EphemeralStorage.UsedBytes, pod.EphemeralStorage.InodesUsed),
+		ratio(pod.EphemeralStorage.InodesUsed, pod.EphemeralStorage.Inodes),
+	)
 }
 
-// setVolumeStorageUsage sets the volume usage for a volume in the provided metrics instance.
-func (c *Collector) setVolumeStorageUsage(pod types.PodSummary, nodeName string) {
+// setPodAnomalyScores sets the anomaly scores of the pod ephemeral storage growth in the snapshot.
+func setPodAnomalyScores(snapshot *metrics.Snapshot, detector *anomaly.Detector, pod types.PodSummary, nodeName string) {
+	scores := detector.Observe(
+		pod.PodRef.Name,
+		pod.PodRef.Namespace,
+		nodeName,
+		snapshot.Timestamp,
+		float64(pod.EphemeralStorage.UsedBytes),
+		float64(pod.EphemeralStorage.InodesUsed),
+	)
+
+	snapshot.SetEphemeralStorageAnomalyScores(
+		pod.PodRef.Name,
+		pod.PodRef.Namespace,
+		nodeName,
+		scores.Used,
+		scores.Inodes,
+	)
+}
+
+// setVolumeStorageUsage sets the volume usage for a volume in the snapshot.
+func setVolumeStorageUsage(snapshot *metrics.Snapshot, pod types.PodSummary, nodeName string) {
 	for _, volume := range pod.Volume {
-		c.Metrics.SetPodVolumeValues(
+		snapshot.SetPodVolumeValues(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -113,7 +216,7 @@ func (c *Collector) setVolumeStorageUsage(pod types.PodSummary, nodeName string)
 			float64(volume.CapacityBytes),
 		)
 
-		c.Metrics.SetPodVolumeInodes(
+		snapshot.SetPodVolumeInodes(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -122,14 +225,42 @@ func (c *Collector) setVolumeStorageUsage(pod types.PodSummary, nodeName string)
 			float64(volume.InodesFree),
 			float64(volume.Inodes),
 		)
+
+		snapshot.SetPodVolumeInodeUsage(
+			pod.PodRef.Name,
+			pod.PodRef.Namespace,
+			nodeName,
+			volume.Name,
+			bytesPerInode(volume.UsedBytes, volume.InodesUsed),
+			ratio(volume.InodesUsed, volume.Inodes),
+		)
 	}
 }
 
-// setContainerStorageUsage sets the storage usage for each container in a pod in the provided metrics instance.
-func (c *Collector) setContainerStorageUsage(pod types.PodSummary, nodeName string) {
+// bytesPerInode returns the average used bytes per used inode, a low value means
+// many small files.
+func bytesPerInode(used, inodesUsed uint64) float64 {
+	if inodesUsed == 0 {
+		return 0
+	}
+
+	return float64(used) / float64(inodesUsed)
+}
+
+// ratio returns the used fraction of the total, or zero when the total is unknown.
+func ratio(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(used) / float64(total)
+}
+
+// setContainerStorageUsage sets the storage usage for each container in a pod in the snapshot.
+func setContainerStorageUsage(snapshot *metrics.Snapshot, pod types.PodSummary, nodeName string) {
 	for _, container := range pod.Containers {
 		// set the memory usage for the container
-		c.Metrics.SetContainerMemoryValues(
+		snapshot.SetContainerMemoryValues(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -140,7 +271,7 @@ func (c *Collector) setContainerStorageUsage(pod types.PodSummary, nodeName stri
 		)
 
 		// set the root filesystem usage for the container
-		c.Metrics.SetContainerRootfsValues(
+		snapshot.SetContainerRootfsValues(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -149,7 +280,7 @@ func (c *Collector) setContainerStorageUsage(pod types.PodSummary, nodeName stri
 			float64(container.Rootfs.AvailableBytes),
 			float64(container.Rootfs.CapacityBytes),
 		)
-		c.Metrics.SetContainerRootfsInodes(
+		snapshot.SetContainerRootfsInodes(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -160,7 +291,7 @@ func (c *Collector) setContainerStorageUsage(pod types.PodSummary, nodeName stri
 		)
 
 		// set the logs usage for the container
-		c.Metrics.SetContainerLogsValues(
+		snapshot.SetContainerLogsValues(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -169,7 +300,7 @@ func (c *Collector) setContainerStorageUsage(pod types.PodSummary, nodeName stri
 			float64(container.Logs.AvailableBytes),
 			float64(container.Logs.CapacityBytes),
 		)
-		c.Metrics.SetContainerLogsInodes(
+		snapshot.SetContainerLogsInodes(
 			pod.PodRef.Name,
 			pod.PodRef.Namespace,
 			nodeName,
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Settings holds the collector options that can be changed while it is running.
+type Settings struct {
+	Interval time.Duration
+	// Namespaces limits the collected pods to these namespaces, if not empty.
+	Namespaces []string
+	// ExcludeNamespaces are the namespaces whose pods are not collected.
+	ExcludeNamespaces []string
+}
+
+// Collects returns true if the pods of the namespace are collected.
+func (s *Settings) Collects(namespace string) bool {
+	if len(s.Namespaces) > 0 && !slices.Contains(s.Namespaces, namespace) {
+		return false
+	}
+
+	return !slices.Contains(s.ExcludeNamespaces, namespace)
+}
+
+// Options holds the current settings of the collectors, which are swapped atomically
+// when the configuration is reloaded.
+type Options struct {
+	settings atomic.Pointer[Settings]
+}
+
+// NewOptions creates the options with the initial settings.
+func NewOptions(settings Settings) *Options {
+	o := &Options{}
+	o.Set(settings)
+
+	return o
+}
+
+// Get returns the current settings.
+func (o *Options) Get() *Settings {
+	return o.settings.Load()
+}
+
+// Set replaces the current settings.
+func (o *Options) Set(settings Settings) {
+	o.settings.Store(&settings)
+}
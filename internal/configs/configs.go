@@ -1,29 +1,164 @@
 package configs
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v10"
+	"gopkg.in/yaml.v3"
 )
 
-// Config holds the configuration for the application.
+// constant values for the supported run modes
+const (
+	ModeNode    = "node"
+	ModeCluster = "cluster"
+)
+
+// envPrefix is the prefix of every environment variable of the application.
+const envPrefix = "LSE_"
+
+// Config holds the configuration for the application. Every field can be set in the
+// configuration file, using the environment variable name without the LSE_ prefix in
+// lower case as the key, and environment variables override the file values.
 type Config struct {
-	Port        int    `env:"LSE_PORT" envDefault:"8080"`
-	Debug       bool   `env:"LSE_DEBUG" envDefault:"false"`
-	JSONLog     bool   `env:"LSE_JSON_LOG" envDefault:"false"`
-	Interval    string `env:"LSE_INTERVAL" envDefault:"10s"`
-	NodeName    string `env:"LSE_NODE_NAME" envDefault:""`
-	CertFile    string `env:"LSE_CERT_FILE" envDefault:"/var/lib/kubelet/pki/kubelet-client-current.pem"`
-	KeyFile     string `env:"LSE_KEY_FILE" envDefault:"/var/lib/kubelet/pki/kubelet-client-current.pem"`
-	K8SLocalAPI string `env:"LSE_K8S_LOCAL_API" envDefault:""`
+	Port               int           `env:"LSE_PORT" envDefault:"8080" yaml:"port" usage:"port of the metrics server"`
+	Debug              bool          `env:"LSE_DEBUG" envDefault:"false" yaml:"debug" usage:"enable debug logs"`
+	JSONLog            bool          `env:"LSE_JSON_LOG" envDefault:"false" yaml:"json_log" usage:"write the logs as JSON"`
+	Interval           time.Duration `env:"LSE_INTERVAL" envDefault:"10s" yaml:"interval" usage:"collection interval"`
+	NodeName           string        `env:"LSE_NODE_NAME" envDefault:"" yaml:"node_name" usage:"name of the node that is collected"`
+	CertFile           string        `env:"LSE_CERT_FILE" envDefault:"/var/lib/kubelet/pki/kubelet-client-current.pem" yaml:"cert_file" usage:"kubelet client certificate file"`
+	KeyFile            string        `env:"LSE_KEY_FILE" envDefault:"/var/lib/kubelet/pki/kubelet-client-current.pem" yaml:"key_file" usage:"kubelet client key file"`
+	K8SLocalAPI        string        `env:"LSE_K8S_LOCAL_API" envDefault:"" yaml:"k8s_local_api" usage:"kubelet summary endpoint"`
+	Source             string        `env:"LSE_SOURCE" envDefault:"kubelet" yaml:"source" usage:"summary source: kubelet, cri, file, proxy or replay"`
+	CRIEndpoint        string        `env:"LSE_CRI_ENDPOINT" envDefault:"unix:///run/containerd/containerd.sock" yaml:"cri_endpoint" usage:"CRI runtime endpoint"`
+	SourceFile         string        `env:"LSE_SOURCE_FILE" envDefault:"" yaml:"source_file" usage:"summary file of the file source, or archive of the replay source"`
+	ReplaySpeed        float64       `env:"LSE_REPLAY_SPEED" envDefault:"1" yaml:"replay_speed" usage:"speed of the replay source relative to the recording, 0 replays a record on every collection"`
+	ReplayLoop         bool          `env:"LSE_REPLAY_LOOP" envDefault:"false" yaml:"replay_loop" usage:"replay the archive from the start after its last record"`
+	APIServer          string        `env:"LSE_API_SERVER" envDefault:"" yaml:"api_server" usage:"API server address, defaults to the in-cluster address"`
+	Namespaces         []string      `env:"LSE_NAMESPACES" envSeparator:"," yaml:"namespaces" usage:"comma separated namespaces to collect, all if empty"`
+	ExcludeNamespaces  []string      `env:"LSE_EXCLUDE_NAMESPACES" envSeparator:"," yaml:"exclude_namespaces" usage:"comma separated namespaces not to collect"`
+	Mode               string        `env:"LSE_MODE" envDefault:"node" yaml:"mode" usage:"run mode: node or cluster"`
+	Workers            int           `env:"LSE_WORKERS" envDefault:"10" yaml:"workers" usage:"number of nodes collected concurrently in cluster mode"`
+	Sharding           bool          `env:"LSE_SHARDING" envDefault:"false" yaml:"sharding" usage:"divide the nodes between the replicas in cluster mode"`
+	PodName            string        `env:"LSE_POD_NAME" envDefault:"" yaml:"pod_name" usage:"name of the exporter pod"`
+	Namespace          string        `env:"LSE_POD_NAMESPACE" envDefault:"default" yaml:"pod_namespace" usage:"namespace of the exporter pod"`
+	LeaseTTL           time.Duration `env:"LSE_LEASE_DURATION" envDefault:"30s" yaml:"lease_duration" usage:"duration of the sharding membership lease"`
+	OTLPAddress        string        `env:"LSE_OTLP_ENDPOINT" envDefault:"" yaml:"otlp_endpoint" usage:"OTLP collector endpoint"`
+	OTLPProto          string        `env:"LSE_OTLP_PROTOCOL" envDefault:"grpc" yaml:"otlp_protocol" usage:"OTLP protocol: grpc or http"`
+	OTLPNoTLS          bool          `env:"LSE_OTLP_INSECURE" envDefault:"false" yaml:"otlp_insecure" usage:"disable TLS for the OTLP exporter"`
+	RWURL              string        `env:"LSE_REMOTE_WRITE_URL" envDefault:"" yaml:"remote_write_url" usage:"remote write endpoint"`
+	RWUsername         string        `env:"LSE_REMOTE_WRITE_USERNAME" envDefault:"" yaml:"remote_write_username" usage:"remote write basic auth username"`
+	RWPassword         string        `env:"LSE_REMOTE_WRITE_PASSWORD" envDefault:"" yaml:"remote_write_password" usage:"remote write basic auth password"`
+	RWToken            string        `env:"LSE_REMOTE_WRITE_BEARER_TOKEN" envDefault:"" yaml:"remote_write_bearer_token" usage:"remote write bearer token"`
+	RWQueueSize        int           `env:"LSE_REMOTE_WRITE_QUEUE_SIZE" envDefault:"100" yaml:"remote_write_queue_size" usage:"number of batches queued for remote write"`
+	RWRetries          int           `env:"LSE_REMOTE_WRITE_MAX_RETRIES" envDefault:"5" yaml:"remote_write_max_retries" usage:"maximum retries of a remote write batch"`
+	InfluxURL          string        `env:"LSE_INFLUX_URL" envDefault:"" yaml:"influx_url" usage:"Influx write endpoint, http(s) or udp"`
+	InfluxToken        string        `env:"LSE_INFLUX_TOKEN" envDefault:"" yaml:"influx_token" usage:"Influx API token"`
+	StatsD             string        `env:"LSE_STATSD_ADDRESS" envDefault:"" yaml:"statsd_address" usage:"DogStatsD address"`
+	SinkTimeout        time.Duration `env:"LSE_SINK_TIMEOUT" envDefault:"5s" yaml:"sink_timeout" usage:"timeout of a write to an output sink"`
+	JSONLOutput        string        `env:"LSE_JSONL_OUTPUT" envDefault:"" yaml:"jsonl_output" usage:"JSON lines output file, or stdout"`
+	JSONLSize          int           `env:"LSE_JSONL_MAX_SIZE_MB" envDefault:"100" yaml:"jsonl_max_size_mb" usage:"maximum size of a JSON lines file in MB"`
+	JSONLFiles         int           `env:"LSE_JSONL_MAX_FILES" envDefault:"5" yaml:"jsonl_max_files" usage:"number of rotated JSON lines files kept"`
+	JSONLGzip          bool          `env:"LSE_JSONL_COMPRESS" envDefault:"true" yaml:"jsonl_compress" usage:"compress the rotated JSON lines files"`
+	Events             bool          `env:"LSE_EVENTS" envDefault:"false" yaml:"events" usage:"emit Kubernetes events on storage threshold breaches"`
+	EventsRatio        float64       `env:"LSE_EVENTS_EPHEMERAL_RATIO" envDefault:"0.9" yaml:"events_ephemeral_ratio" usage:"fraction of the ephemeral-storage limit that triggers an event"`
+	EventsSpace        string        `env:"LSE_EVENTS_VOLUME_MIN_AVAILABLE" envDefault:"1Gi" yaml:"events_volume_min_available" usage:"available space of a volume below which an event is emitted"`
+	EventsDedup        time.Duration `env:"LSE_EVENTS_DEDUP" envDefault:"30m" yaml:"events_dedup" usage:"period in which the same event is not emitted again"`
+	EventsRate         int           `env:"LSE_EVENTS_RATE" envDefault:"10" yaml:"events_rate" usage:"maximum number of events per minute"`
+	Anomaly            bool          `env:"LSE_ANOMALY" envDefault:"false" yaml:"anomaly" usage:"score the pod ephemeral storage growth against its moving average"`
+	AnomalyAlpha       float64       `env:"LSE_ANOMALY_ALPHA" envDefault:"0.1" yaml:"anomaly_alpha" usage:"weight of the latest growth rate in the moving average"`
+	AnomalyThreshold   float64       `env:"LSE_ANOMALY_THRESHOLD" envDefault:"4" yaml:"anomaly_threshold" usage:"score above which a growth is reported as an anomaly"`
+	AnomalyWarmup      int           `env:"LSE_ANOMALY_WARMUP" envDefault:"10" yaml:"anomaly_warmup" usage:"number of collections before the anomaly scores are computed"`
+	AlertRules         string        `env:"LSE_ALERT_RULES" envDefault:"" yaml:"alert_rules" usage:"alerting rules file"`
+	HistoryRetention   time.Duration `env:"LSE_HISTORY_RETENTION" envDefault:"1h" yaml:"history_retention" usage:"period of the in-memory history of every series, 0 disables it"`
+	HistorySeries      int           `env:"LSE_HISTORY_MAX_SERIES" envDefault:"10000" yaml:"history_max_series" usage:"maximum number of series in the in-memory history, 0 does not limit it"`
+	CheckpointFile     string        `env:"LSE_CHECKPOINT_FILE" envDefault:"" yaml:"checkpoint_file" usage:"file that keeps the history and the alert states across restarts"`
+	CheckpointInterval time.Duration `env:"LSE_CHECKPOINT_INTERVAL" envDefault:"1m" yaml:"checkpoint_interval" usage:"interval of the checkpoint writes"`
+	UI                 bool          `env:"LSE_UI" envDefault:"true" yaml:"ui" usage:"serve the web UI on /ui/ of the metrics server"`
 }
 
-// LoadConfig loads the configuration from environment variables using the caarlos0/env package.
-func LoadConfig() (*Config, error) {
+// LoadConfig loads the configuration from the defaults, the optional configuration file
+// (YAML or JSON), the environment variables and the command-line flags, in that order,
+// and validates it. The flags are keyed by the environment variable of their field.
+func LoadConfig(path string, flags map[string]string) (*Config, error) {
+	// start from the default values
 	cfg := Config{}
-	if err := env.Parse(&cfg); err != nil {
+	if err := env.ParseWithOptions(&cfg, env.Options{Environment: map[string]string{}}); err != nil {
+		return nil, fmt.Errorf("failed to set default values: %w", err)
+	}
+
+	// apply the configuration file
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// apply the environment variables that are set
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(key, envPrefix) {
+			vars[key] = value
+		}
+	}
+
+	if err := override(&cfg, vars); err != nil {
 		return nil, fmt.Errorf("failed to parse environment variables: %w", err)
 	}
 
+	// apply the command-line flags
+	if err := override(&cfg, flags); err != nil {
+		return nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// loadFile decodes the configuration file into the config. Unknown keys are rejected,
+// so typos do not silently fall back to the default values.
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// override sets the fields whose environment variable names are in the values.
+func override(cfg *Config, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	over := Config{}
+	if err := env.ParseWithOptions(&over, env.Options{Environment: values}); err != nil {
+		return err
+	}
+
+	dst, src := reflect.ValueOf(cfg).Elem(), reflect.ValueOf(over)
+	for i := range dst.NumField() {
+		if _, ok := values[dst.Type().Field(i).Tag.Get("env")]; ok {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package configs
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// Flags registers a command-line flag for every config field, named after its config
+// key with dashes, e.g. --node-name, and collects the values that are set.
+type Flags struct {
+	// Path is the configuration file, set by the --config flag.
+	Path   string
+	values map[string]string
+}
+
+// flagValue stores the raw value of a flag by the environment variable of its field,
+// so it is parsed the same way as the environment variables.
+type flagValue struct {
+	name   string
+	isBool bool
+	values map[string]string
+}
+
+func (v *flagValue) String() string {
+	if v == nil || v.values == nil {
+		return ""
+	}
+
+	return v.values[v.name]
+}
+
+func (v *flagValue) Set(value string) error {
+	v.values[v.name] = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// NewFlags registers the --config flag and the config field flags on the flag set.
+// The configuration file defaults to the LSE_CONFIG_FILE environment variable.
+func NewFlags(fs *flag.FlagSet, defaultPath string) *Flags {
+	f := &Flags{values: make(map[string]string)}
+	fs.StringVar(&f.Path, "config", defaultPath, "configuration file (YAML or JSON)")
+
+	t := reflect.TypeOf(Config{})
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		usage := field.Tag.Get("usage")
+		if def := field.Tag.Get("envDefault"); def != "" {
+			usage += " (default " + def + ")"
+		}
+
+		// the back quoted environment variable is printed as the value name of the flag
+		value := &flagValue{
+			name:   field.Tag.Get("env"),
+			isBool: field.Type.Kind() == reflect.Bool,
+			values: f.values,
+		}
+		if value.isBool {
+			usage += " [" + value.name + "]"
+		} else {
+			usage += " [`" + value.name + "`]"
+		}
+
+		fs.Var(value, strings.ReplaceAll(field.Tag.Get("yaml"), "_", "-"), usage)
+	}
+
+	return f
+}
+
+// Values returns the values of the flags that are set, by the environment variable of their field.
+func (f *Flags) Values() map[string]string {
+	return f.values
+}
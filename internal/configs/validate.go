@@ -0,0 +1,67 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/amirhnajafiz/localsight/internal/kube"
+	"github.com/amirhnajafiz/localsight/internal/otlp"
+	"github.com/amirhnajafiz/localsight/internal/sources"
+)
+
+// Validate checks the configuration values and returns every invalid value at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port < 65536, "port must be between 1 and 65535, got %d", c.Port)
+	check(c.Interval > 0, "interval must be positive, got %s", c.Interval)
+	check(slices.Contains([]string{ModeNode, ModeCluster}, c.Mode), "mode must be %s or %s, got %q", ModeNode, ModeCluster, c.Mode)
+	check(
+		slices.Contains([]string{sources.SourceKubelet, sources.SourceCRI, sources.SourceFile, sources.SourceProxy, sources.SourceReplay}, c.Source),
+		"source must be kubelet, cri, file, proxy or replay, got %q", c.Source,
+	)
+	check(c.Source != sources.SourceFile || c.SourceFile != "", "source_file is required when the source is file")
+	check(c.Source != sources.SourceReplay || c.SourceFile != "", "source_file is required when the source is replay")
+	check(c.ReplaySpeed >= 0, "replay_speed must not be negative, got %g", c.ReplaySpeed)
+	check(c.Workers > 0, "workers must be positive, got %d", c.Workers)
+	check(!c.Sharding || c.PodName != "", "pod_name is required when sharding is enabled")
+	check(!c.Sharding || c.LeaseTTL > 0, "lease_duration must be positive, got %s", c.LeaseTTL)
+	check(c.OTLPProto == otlp.ProtocolGRPC || c.OTLPProto == otlp.ProtocolHTTP, "otlp_protocol must be grpc or http, got %q", c.OTLPProto)
+	check(c.RWQueueSize > 0, "remote_write_queue_size must be positive, got %d", c.RWQueueSize)
+	check(c.RWRetries >= 0, "remote_write_max_retries must not be negative, got %d", c.RWRetries)
+	check(c.HistoryRetention >= 0, "history_retention must not be negative, got %s", c.HistoryRetention)
+	check(c.HistorySeries >= 0, "history_max_series must not be negative, got %d", c.HistorySeries)
+	check(c.CheckpointFile == "" || c.CheckpointInterval > 0, "checkpoint_interval must be positive, got %s", c.CheckpointInterval)
+	check(c.SinkTimeout > 0, "sink_timeout must be positive, got %s", c.SinkTimeout)
+	check(c.JSONLSize > 0, "jsonl_max_size_mb must be positive, got %d", c.JSONLSize)
+	check(c.JSONLFiles >= 0, "jsonl_max_files must not be negative, got %d", c.JSONLFiles)
+	check(c.AnomalyAlpha > 0 && c.AnomalyAlpha <= 1, "anomaly_alpha must be between 0 and 1, got %g", c.AnomalyAlpha)
+	check(c.AnomalyThreshold > 0, "anomaly_threshold must be positive, got %g", c.AnomalyThreshold)
+	check(c.AnomalyWarmup >= 0, "anomaly_warmup must not be negative, got %d", c.AnomalyWarmup)
+	check(c.EventsRatio >= 0 && c.EventsRatio <= 1, "events_ephemeral_ratio must be between 0 and 1, got %g", c.EventsRatio)
+	check(c.EventsDedup >= 0, "events_dedup must not be negative, got %s", c.EventsDedup)
+	check(c.EventsRate > 0, "events_rate must be positive, got %d", c.EventsRate)
+
+	if c.EventsSpace != "" {
+		if _, err := kube.ParseQuantity(c.EventsSpace); err != nil {
+			errs = append(errs, fmt.Errorf("events_volume_min_available is not a valid quantity: %w", err))
+		}
+	}
+
+	for _, namespace := range c.Namespaces {
+		check(!slices.Contains(c.ExcludeNamespaces, namespace), "namespace %q is both included and excluded", namespace)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
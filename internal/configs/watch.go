@@ -0,0 +1,110 @@
+package configs
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reloadable lists the fields that are applied without a restart.
+var reloadable = map[string]bool{
+	"Interval":          true,
+	"Namespaces":        true,
+	"ExcludeNamespaces": true,
+	"EventsRatio":       true,
+	"EventsSpace":       true,
+	"EventsDedup":       true,
+	"EventsRate":        true,
+	"AlertRules":        true,
+}
+
+// Watch reloads the configuration when the file changes or the process receives SIGHUP,
+// and calls apply with the new configuration. An invalid configuration is logged and the
+// running one is kept. The flags are applied on every reload. The changes of the fields
+// that are not reloadable are ignored until the next restart.
+func Watch(path string, flags map[string]string, period time.Duration, logr *zap.Logger, current *Config, apply func(*Config) error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	modified := modTime(path)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			logr.Info("received SIGHUP, reloading configuration")
+		case <-ticker.C:
+			// check the file for changes
+			if path == "" {
+				continue
+			}
+
+			mt := modTime(path)
+			if mt.Equal(modified) {
+				continue
+			}
+
+			modified = mt
+			logr.Info("configuration file changed, reloading", zap.String("path", path))
+		}
+
+		next, err := LoadConfig(path, flags)
+		if err != nil {
+			logr.Error("failed to reload configuration, keeping the current one", zap.Error(err))
+			continue
+		}
+
+		merged, ignored := merge(current, next)
+		if len(ignored) > 0 {
+			logr.Warn("configuration changes that require a restart are ignored", zap.Strings("fields", ignored))
+		}
+
+		if err := apply(merged); err != nil {
+			logr.Error("failed to apply configuration", zap.Error(err))
+			continue
+		}
+
+		current = merged
+		logr.Info("configuration reloaded")
+	}
+}
+
+// merge returns the current configuration with the reloadable fields of the next one,
+// and the config keys of the changed fields that are not reloadable.
+func merge(current, next *Config) (*Config, []string) {
+	merged := *current
+	var ignored []string
+
+	dst, src := reflect.ValueOf(&merged).Elem(), reflect.ValueOf(*next)
+	for i := range dst.NumField() {
+		field := dst.Type().Field(i)
+
+		switch {
+		case reloadable[field.Name]:
+			dst.Field(i).Set(src.Field(i))
+		case !reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()):
+			ignored = append(ignored, field.Tag.Get("yaml"))
+		}
+	}
+
+	return &merged, ignored
+}
+
+// modTime returns the modification time of the file, or the zero time if it cannot be read.
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,301 @@
+package fakekubelet
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// constant values of the node file system, shared by the pods, containers and volumes
+const (
+	fsCapacity    = 100 << 30
+	fsInodes      = 6553600
+	systemUsed    = 20 << 30
+	systemInodes  = 250000
+	volumeSize    = 10 << 30
+	volumeInodes  = 655360
+	memoryLimit   = 2 << 30
+	smallFileSize = 4 << 10
+)
+
+// names of the generated apps, containers and volumes
+var (
+	appNames       = []string{"web", "api", "worker", "cache", "db", "ingest", "scheduler", "frontend"}
+	containerNames = []string{"app", "sidecar", "proxy", "agent"}
+	volumeNames    = []string{"data", "cache", "scratch"}
+)
+
+// pod is the usage state of a fake pod.
+type pod struct {
+	namespace  string
+	name       string
+	uid        string
+	containers []*container
+	volumes    []*volume
+}
+
+// container is the usage state of a fake container.
+type container struct {
+	name         string
+	rootfs       float64
+	rootfsInodes float64
+	logs         float64
+	logsInodes   float64
+	memory       uint64
+}
+
+// volume is the usage state of a fake volume.
+type volume struct {
+	name   string
+	used   float64
+	inodes float64
+}
+
+// node generates the pods of the scenario and applies its behaviors.
+type node struct {
+	scenario *Scenario
+	rand     *rand.Rand
+	pods     []*pod
+	created  int
+	// last is the elapsed time of the last update, and churns the next churn of each behavior
+	last   time.Duration
+	churns map[int]time.Duration
+}
+
+// newNode creates the pods of the scenario.
+func newNode(scenario *Scenario) *node {
+	n := &node{
+		scenario: scenario,
+		rand:     rand.New(rand.NewSource(scenario.Seed)),
+		churns:   make(map[int]time.Duration),
+	}
+
+	for range scenario.Pods {
+		n.pods = append(n.pods, n.newPod(scenario.Namespaces[n.created%len(scenario.Namespaces)]))
+	}
+
+	for i, b := range scenario.Behaviors {
+		if b.Kind == KindPodChurn {
+			n.churns[i] = b.Start + b.Every
+		}
+	}
+
+	return n
+}
+
+// newPod creates a pod with random initial usage in the namespace.
+func (n *node) newPod(namespace string) *pod {
+	n.created++
+
+	p := &pod{
+		namespace: namespace,
+		name:      fmt.Sprintf("%s-%s", appNames[n.created%len(appNames)], n.suffix(5)),
+		uid: fmt.Sprintf(
+			"%08x-%04x-%04x-%04x-%012x",
+			n.rand.Uint32(), n.rand.Intn(1<<16), n.rand.Intn(1<<16), n.rand.Intn(1<<16), n.rand.Int63n(1<<48),
+		),
+	}
+
+	for i := range n.scenario.Containers {
+		p.containers = append(p.containers, &container{
+			name:         pick(containerNames, i, "container"),
+			rootfs:       float64(1<<20 + n.rand.Int63n(200<<20)),
+			rootfsInodes: float64(100 + n.rand.Int63n(5000)),
+			logs:         float64(n.rand.Int63n(50 << 20)),
+			logsInodes:   float64(1 + n.rand.Int63n(10)),
+			memory:       uint64(10<<20 + n.rand.Int63n(500<<20)),
+		})
+	}
+
+	for i := range n.scenario.Volumes {
+		p.volumes = append(p.volumes, &volume{
+			name:   pick(volumeNames, i, "volume"),
+			used:   float64(n.rand.Int63n(500 << 20)),
+			inodes: float64(1 + n.rand.Int63n(1000)),
+		})
+	}
+
+	return p
+}
+
+// suffix returns a random suffix of lowercase letters and digits.
+func (n *node) suffix(length int) string {
+	const alphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = alphabet[n.rand.Intn(len(alphabet))]
+	}
+
+	return string(b)
+}
+
+// chance returns true with the probability, or always when it is zero.
+func (n *node) chance(probability float64) bool {
+	return probability == 0 || n.rand.Float64() < probability
+}
+
+// pick returns the name at the index, or a numbered name after the list.
+func pick(names []string, i int, prefix string) string {
+	if i < len(names) {
+		return names[i]
+	}
+
+	return fmt.Sprintf("%s-%d", prefix, i)
+}
+
+// update applies the behaviors of the scenario from the last update to the elapsed time.
+func (n *node) update(elapsed time.Duration) {
+	from := n.last
+	n.last = max(elapsed, n.last)
+
+	for i, b := range n.scenario.Behaviors {
+		seconds := b.window(from, elapsed).Seconds()
+
+		switch b.Kind {
+		case KindLinearGrowth:
+			n.each(&b, func(p *pod) {
+				p.containers[0].rootfs += b.rate * seconds
+			})
+		case KindLogBurst:
+			n.each(&b, func(p *pod) {
+				for _, c := range p.containers {
+					c.logs += b.rate * seconds
+				}
+			})
+		case KindInodeStorm:
+			n.each(&b, func(p *pod) {
+				p.containers[0].rootfsInodes += b.rate * seconds
+				p.containers[0].rootfs += b.rate * seconds * smallFileSize
+			})
+		case KindPodChurn:
+			for n.churns[i] <= elapsed && (b.Duration == 0 || n.churns[i] <= b.Start+b.Duration) {
+				n.churn(&b)
+				n.churns[i] += b.Every
+			}
+		}
+	}
+}
+
+// each calls the function for every pod that matches the behavior.
+func (n *node) each(b *Behavior, f func(p *pod)) {
+	for _, p := range n.pods {
+		if b.matches(p.namespace, p.name) {
+			f(p)
+		}
+	}
+}
+
+// churn replaces random matching pods with new pods in the same namespaces.
+func (n *node) churn(b *Behavior) {
+	for range b.Count {
+		var matching []int
+		for i, p := range n.pods {
+			if b.matches(p.namespace, p.name) {
+				matching = append(matching, i)
+			}
+		}
+
+		if len(matching) == 0 {
+			return
+		}
+
+		i := matching[n.rand.Intn(len(matching))]
+		n.pods[i] = n.newPod(n.pods[i].namespace)
+	}
+}
+
+// delete removes the pod and returns true when it exists.
+func (n *node) delete(namespace, name string) bool {
+	for i, p := range n.pods {
+		if p.namespace == namespace && p.name == name {
+			n.pods = append(n.pods[:i], n.pods[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// summary builds the kubelet summary of the current usage.
+func (n *node) summary() *types.Summary {
+	used, inodes := float64(systemUsed), float64(systemInodes)
+	for _, p := range n.pods {
+		for _, c := range p.containers {
+			used += c.rootfs + c.logs
+			inodes += c.rootfsInodes + c.logsInodes
+		}
+		for _, v := range p.volumes {
+			used += v.used
+			inodes += v.inodes
+		}
+	}
+
+	available := uint64(max(fsCapacity-used, 0))
+	inodesFree := uint64(max(fsInodes-inodes, 0))
+
+	summary := &types.Summary{Pods: make([]types.PodSummary, 0, len(n.pods))}
+	summary.Node.NodeName = n.scenario.NodeName
+
+	for _, p := range n.pods {
+		var ps types.PodSummary
+		ps.PodRef.Name = p.name
+		ps.PodRef.Namespace = p.namespace
+		ps.PodRef.UID = p.uid
+
+		var podUsed, podInodes float64
+		for _, c := range p.containers {
+			var cs types.ContainerSummary
+			cs.Name = c.name
+
+			cs.Memory.UsageBytes = c.memory
+			cs.Memory.CapacityBytes = memoryLimit
+			cs.Memory.AvailableBytes = memoryLimit - min(c.memory, memoryLimit)
+
+			cs.Rootfs.UsedBytes = uint64(c.rootfs)
+			cs.Rootfs.CapacityBytes = fsCapacity
+			cs.Rootfs.AvailableBytes = available
+			cs.Rootfs.InodesUsed = uint64(c.rootfsInodes)
+			cs.Rootfs.Inodes = fsInodes
+			cs.Rootfs.InodesFree = inodesFree
+
+			cs.Logs.UsedBytes = uint64(c.logs)
+			cs.Logs.CapacityBytes = fsCapacity
+			cs.Logs.AvailableBytes = available
+			cs.Logs.InodesUsed = uint64(c.logsInodes)
+			cs.Logs.Inodes = fsInodes
+			cs.Logs.InodesFree = inodesFree
+
+			ps.Containers = append(ps.Containers, cs)
+			podUsed += c.rootfs + c.logs
+			podInodes += c.rootfsInodes + c.logsInodes
+		}
+
+		for _, v := range p.volumes {
+			ps.Volume = append(ps.Volume, types.VolumeSummary{
+				Name:           v.name,
+				UsedBytes:      uint64(v.used),
+				CapacityBytes:  volumeSize,
+				AvailableBytes: uint64(max(volumeSize-v.used, 0)),
+				InodesUsed:     uint64(v.inodes),
+				Inodes:         volumeInodes,
+				InodesFree:     uint64(max(volumeInodes-v.inodes, 0)),
+			})
+			podUsed += v.used
+			podInodes += v.inodes
+		}
+
+		ps.EphemeralStorage.UsedBytes = uint64(podUsed)
+		ps.EphemeralStorage.CapacityBytes = fsCapacity
+		ps.EphemeralStorage.AvailableBytes = available
+		ps.EphemeralStorage.InodesUsed = uint64(podInodes)
+		ps.EphemeralStorage.Inodes = fsInodes
+		ps.EphemeralStorage.InodesFree = inodesFree
+
+		summary.Pods = append(summary.Pods, ps)
+	}
+
+	return summary
+}
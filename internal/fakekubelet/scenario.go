@@ -0,0 +1,184 @@
+package fakekubelet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/kube"
+
+	"gopkg.in/yaml.v3"
+)
+
+// constant values for the behavior kinds of a scenario
+const (
+	// KindLinearGrowth grows the writable layer of the first container of the pods by rate bytes per second.
+	KindLinearGrowth = "linear_growth"
+	// KindLogBurst grows the logs of every container of the pods by rate bytes per second.
+	KindLogBurst = "log_burst"
+	// KindPodChurn replaces count of the pods with new pods on every period.
+	KindPodChurn = "pod_churn"
+	// KindInodeStorm creates rate small files per second in the first container of the pods.
+	KindInodeStorm = "inode_storm"
+	// KindErrors answers the requests with internal server errors.
+	KindErrors = "errors"
+	// KindSlow delays the responses.
+	KindSlow = "slow"
+	// KindAuthFailure rejects the requests as unauthorized.
+	KindAuthFailure = "auth_failure"
+)
+
+// Scenario describes the pods of the fake node and the scripted behaviors applied to them.
+type Scenario struct {
+	NodeName   string     `yaml:"node_name"`
+	Pods       int        `yaml:"pods"`
+	Containers int        `yaml:"containers"`
+	Volumes    int        `yaml:"volumes"`
+	Namespaces []string   `yaml:"namespaces"`
+	Seed       int64      `yaml:"seed"`
+	Behaviors  []Behavior `yaml:"behaviors"`
+}
+
+// Behavior is a scripted behavior of the fake kubelet. It is active from its start,
+// relative to the start of the server, for its duration, or until the end when the
+// duration is zero.
+type Behavior struct {
+	Kind     string        `yaml:"kind"`
+	Start    time.Duration `yaml:"start"`
+	Duration time.Duration `yaml:"duration"`
+	// Match is a path.Match pattern of the namespace/name of the affected pods, all pods when empty.
+	Match string `yaml:"match"`
+	// Rate is a quantity per second, e.g. 1Mi, of the growth behaviors.
+	Rate string `yaml:"rate"`
+	// Every and Count are the period and the number of replaced pods of the churn behavior.
+	Every time.Duration `yaml:"every"`
+	Count int           `yaml:"count"`
+	// Delay is the response delay of the slow behavior.
+	Delay time.Duration `yaml:"delay"`
+	// Probability is the fraction of the requests affected by the request behaviors, all when zero.
+	Probability float64 `yaml:"probability"`
+
+	rate float64
+}
+
+// DefaultScenario returns a scenario of a small node without behaviors.
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		NodeName:   "fake-node",
+		Pods:       10,
+		Containers: 2,
+		Volumes:    1,
+		Namespaces: []string{"default"},
+		Seed:       1,
+	}
+}
+
+// LoadScenario reads a scenario file. The missing fields keep the default values.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+
+	scenario := DefaultScenario()
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(scenario); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse scenario file %s: %w", path, err)
+	}
+
+	return scenario, nil
+}
+
+// validate checks the scenario and parses the rates of its behaviors.
+func (s *Scenario) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(s.NodeName != "", "node_name is required")
+	check(s.Pods >= 0, "pods must not be negative, got %d", s.Pods)
+	check(s.Containers > 0, "containers must be positive, got %d", s.Containers)
+	check(s.Volumes >= 0, "volumes must not be negative, got %d", s.Volumes)
+	check(len(s.Namespaces) > 0, "namespaces must not be empty")
+
+	for i := range s.Behaviors {
+		errs = append(errs, s.Behaviors[i].validate(i))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid scenario: %w", err)
+	}
+
+	return nil
+}
+
+// validate checks the behavior and parses its rate.
+func (b *Behavior) validate(i int) error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("behavior %d (%s): "+format, append([]any{i, b.Kind}, args...)...))
+		}
+	}
+
+	check(b.Start >= 0, "start must not be negative, got %s", b.Start)
+	check(b.Duration >= 0, "duration must not be negative, got %s", b.Duration)
+	check(b.Probability >= 0 && b.Probability <= 1, "probability must be between 0 and 1, got %g", b.Probability)
+
+	if _, err := path.Match(b.Match, ""); err != nil {
+		check(false, "invalid match pattern %q: %v", b.Match, err)
+	}
+
+	switch b.Kind {
+	case KindLinearGrowth, KindLogBurst, KindInodeStorm:
+		rate, err := kube.ParseQuantity(b.Rate)
+		check(err == nil, "invalid rate %q: %v", b.Rate, err)
+		b.rate = float64(rate)
+	case KindPodChurn:
+		check(b.Every > 0, "every must be positive, got %s", b.Every)
+		check(b.Count > 0, "count must be positive, got %d", b.Count)
+	case KindSlow:
+		check(b.Delay > 0, "delay must be positive, got %s", b.Delay)
+	case KindErrors, KindAuthFailure:
+	default:
+		check(false, "unknown kind")
+	}
+
+	return errors.Join(errs...)
+}
+
+// window returns the part of the period between from and to in which the behavior is active.
+func (b *Behavior) window(from, to time.Duration) time.Duration {
+	from = max(from, b.Start)
+	if b.Duration > 0 {
+		to = min(to, b.Start+b.Duration)
+	}
+
+	return max(to-from, 0)
+}
+
+// active returns true when the behavior is active at the elapsed time.
+func (b *Behavior) active(elapsed time.Duration) bool {
+	return elapsed >= b.Start && (b.Duration == 0 || elapsed < b.Start+b.Duration)
+}
+
+// matches returns true when the behavior affects the pod.
+func (b *Behavior) matches(namespace, name string) bool {
+	if b.Match == "" {
+		return true
+	}
+
+	ok, _ := path.Match(b.Match, namespace+"/"+name)
+
+	return ok
+}
@@ -0,0 +1,261 @@
+package fakekubelet
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the configuration of the fake kubelet.
+type Config struct {
+	Scenario *Scenario
+	// Certificate is the server certificate, a self-signed one is created when it is nil.
+	Certificate *tls.Certificate
+	// ClientCAs accepts the client certificates signed by them when set.
+	ClientCAs *x509.CertPool
+	// Token accepts the requests with this bearer token when set.
+	Token string
+}
+
+// Server is a fake kubelet that serves the summary API of a generated node. The usage of
+// the pods changes with the behaviors of the scenario, relative to the server start.
+// When client CAs or a token are set, a request is accepted with either of them, like
+// the kubelet does.
+type Server struct {
+	token string
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+
+	lock     sync.Mutex
+	scenario *Scenario
+	node     *node
+	start    time.Time
+}
+
+// New creates a fake kubelet for the configuration.
+func New(cfg Config) (*Server, error) {
+	scenario := cfg.Scenario
+	if scenario == nil {
+		scenario = DefaultScenario()
+	}
+
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+
+	cert := cfg.Certificate
+	if cert == nil {
+		selfSigned, err := SelfSigned()
+		if err != nil {
+			return nil, err
+		}
+
+		cert = &selfSigned
+	}
+
+	s := &Server{
+		token:    cfg.Token,
+		scenario: scenario,
+		node:     newNode(scenario),
+		start:    time.Now(),
+	}
+	s.cert.Store(cert)
+	s.clientCAs.Store(cfg.ClientCAs)
+
+	return s, nil
+}
+
+// TLSConfig returns the TLS configuration of the server, which picks up the rotated
+// certificate and client CAs on every handshake.
+func (s *Server) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*s.cert.Load()},
+			}
+
+			if pool := s.clientCAs.Load(); pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+
+			return cfg, nil
+		},
+	}
+}
+
+// Serve serves the summary API over TLS on the listener until it is closed.
+func (s *Server) Serve(l net.Listener) error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return server.Serve(tls.NewListener(l, s.TLSConfig()))
+}
+
+// SetCertificate replaces the server certificate.
+func (s *Server) SetCertificate(cert tls.Certificate) {
+	s.cert.Store(&cert)
+}
+
+// SetClientCAs replaces the authorities of the accepted client certificates.
+func (s *Server) SetClientCAs(pool *x509.CertPool) {
+	s.clientCAs.Store(pool)
+}
+
+// AddBehavior adds a behavior to the scenario, with a start relative to now.
+func (s *Server) AddBehavior(b Behavior) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := b.validate(len(s.scenario.Behaviors)); err != nil {
+		return err
+	}
+
+	// apply the behaviors up to now, so the new one does not apply to the past
+	elapsed := time.Since(s.start)
+	s.node.update(elapsed)
+
+	b.Start += elapsed
+	s.scenario.Behaviors = append(s.scenario.Behaviors, b)
+	if b.Kind == KindPodChurn {
+		s.node.churns[len(s.scenario.Behaviors)-1] = b.Start + b.Every
+	}
+
+	return nil
+}
+
+// DeletePod removes a pod from the node and returns true when it exists.
+func (s *Server) DeletePod(namespace, name string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.node.delete(namespace, name)
+}
+
+// Pods returns the namespace/name of the pods of the node.
+func (s *Server) Pods() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	list := make([]string, 0, len(s.node.pods))
+	for _, p := range s.node.pods {
+		list = append(list, p.namespace+"/"+p.name)
+	}
+
+	return list
+}
+
+// Handler returns the HTTP handler of the summary API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats/summary", s.handleSummary)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	return mux
+}
+
+// handleSummary authorizes the request, applies the request behaviors and writes the summary.
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.lock.Lock()
+	elapsed := time.Since(s.start)
+	auth, fail, delay := s.requestBehaviors(elapsed)
+	s.lock.Unlock()
+
+	if auth {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fail {
+		http.Error(w, "Internal Server Error: failed to get node info", http.StatusInternalServerError)
+		return
+	}
+
+	s.lock.Lock()
+	s.node.update(time.Since(s.start))
+	data, err := json.Marshal(s.node.summary())
+	s.lock.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// authorized returns true when the request has a verified client certificate or the
+// bearer token, or when the server requires neither.
+func (s *Server) authorized(r *http.Request) bool {
+	pool := s.clientCAs.Load()
+	if pool == nil && s.token == "" {
+		return true
+	}
+
+	if pool != nil && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return true
+	}
+
+	if s.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+	}
+
+	return false
+}
+
+// requestBehaviors returns whether the request is rejected or failed, and its delay,
+// by the request behaviors that are active at the elapsed time.
+func (s *Server) requestBehaviors(elapsed time.Duration) (bool, bool, time.Duration) {
+	var (
+		auth, fail bool
+		delay      time.Duration
+	)
+
+	for _, b := range s.scenario.Behaviors {
+		if !b.active(elapsed) {
+			continue
+		}
+
+		switch b.Kind {
+		case KindAuthFailure:
+			auth = auth || s.node.chance(b.Probability)
+		case KindErrors:
+			fail = fail || s.node.chance(b.Probability)
+		case KindSlow:
+			if s.node.chance(b.Probability) {
+				delay = max(delay, b.Delay)
+			}
+		}
+	}
+
+	return auth, fail, delay
+}
@@ -0,0 +1,230 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// constant values of the dashboard layout
+const (
+	panelWidth  = 8
+	panelHeight = 8
+	gridWidth   = 24
+)
+
+// DashboardOptions holds the options of the generated Grafana dashboard.
+type DashboardOptions struct {
+	Title string
+	UID   string
+	// TopK is the number of series shown in each panel.
+	TopK int
+}
+
+// panel is a Grafana dashboard panel.
+type panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	GridPos     gridPos        `json:"gridPos"`
+	Datasource  map[string]any `json:"datasource,omitempty"`
+	Targets     []target       `json:"targets,omitempty"`
+	FieldConfig map[string]any `json:"fieldConfig,omitempty"`
+	Collapsed   *bool          `json:"collapsed,omitempty"`
+	Panels      []panel        `json:"panels,omitempty"`
+}
+
+type gridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+// layout places the panels on the dashboard grid.
+type layout struct {
+	panels []panel
+	id     int
+	x, y   int
+}
+
+// Dashboard writes a Grafana dashboard JSON with the panels of every metric subsystem.
+func Dashboard(w io.Writer, opts DashboardOptions) error {
+	l := &layout{}
+
+	// exporter health
+	l.row("Exporter")
+	l.add(metrics.APIStatus.Help, "", metrics.APIStatus.FQName()+filter(metrics.APIStatus.Labels), "{{exported_node}}", "none")
+	l.add(metrics.APILatency.Help, "", metrics.APILatency.FQName()+filter(metrics.APILatency.Labels), "{{exported_node}}", "s")
+
+	// storage subsystems
+	for _, g := range groups() {
+		l.row(g.Title())
+
+		sel := filter(g.Labels())
+		legend := legendFormat(g.Labels())
+
+		if g.Used != nil {
+			l.add(g.Title()+" used", g.Used.Help, topk(opts.TopK, g.Used.FQName()+sel), legend, "bytes")
+		}
+		if g.Used != nil && g.Capacity != nil {
+			expr := fmt.Sprintf("%s%s / %s%s", g.Used.FQName(), sel, g.Capacity.FQName(), sel)
+			l.add(g.Title()+" used ratio", "Used bytes as a fraction of the capacity", topk(opts.TopK, expr), legend, "percentunit")
+		}
+		if g.InodesUsed != nil && g.InodesTotal != nil {
+			expr := fmt.Sprintf("%s%s / %s%s", g.InodesUsed.FQName(), sel, g.InodesTotal.FQName(), sel)
+			l.add(g.Title()+" inodes used ratio", "Used inodes as a fraction of the total inodes", topk(opts.TopK, expr), legend, "percentunit")
+		}
+		if g.BytesPerInode != nil {
+			// the lowest averages of the series with used inodes point at many small files
+			expr := fmt.Sprintf("bottomk(%d, %s%s > 0)", opts.TopK, g.BytesPerInode.FQName(), sel)
+			l.add(g.Title()+" bytes per inode", g.BytesPerInode.Help, expr, legend, "bytes")
+		}
+	}
+
+	// the pods with the most used inodes, ranked by the query instead of an exported rank,
+	// so the series do not churn when the pods swap places
+	l.row("Top Inodes")
+	l.add(
+		"Pods with the most used inodes",
+		metrics.EphemeralStorageInodesUsed.Help,
+		topk(opts.TopK, metrics.EphemeralStorageInodesUsed.FQName()+filter(metrics.EphemeralStorageInodesUsed.Labels)),
+		"{{exported_namespace}}/{{exported_pod}} on {{exported_node}}",
+		"short",
+	)
+
+	// container memory is not a storage subsystem, but it is exported as well
+	memory := filter(metrics.ContainerMemoryUsageBytes.Labels)
+	l.row("Container Memory")
+	l.add(
+		"Container memory usage",
+		metrics.ContainerMemoryUsageBytes.Help,
+		topk(opts.TopK, metrics.ContainerMemoryUsageBytes.FQName()+memory),
+		legendFormat(metrics.ContainerMemoryUsageBytes.Labels),
+		"bytes",
+	)
+
+	dashboard := map[string]any{
+		"title":         opts.Title,
+		"uid":           opts.UID,
+		"tags":          []string{"localsight"},
+		"timezone":      "browser",
+		"schemaVersion": 39,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating":    map[string]any{"list": variables()},
+		"panels":        l.panels,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(dashboard)
+}
+
+// row starts a new row of panels.
+func (l *layout) row(title string) {
+	if l.x > 0 {
+		l.x, l.y = 0, l.y+panelHeight
+	}
+
+	l.id++
+	collapsed := false
+	l.panels = append(l.panels, panel{
+		ID:        l.id,
+		Type:      "row",
+		Title:     title,
+		GridPos:   gridPos{X: 0, Y: l.y, W: gridWidth, H: 1},
+		Collapsed: &collapsed,
+	})
+
+	l.y++
+}
+
+// add places a time series panel next to the previous one.
+func (l *layout) add(title, description, expr, legend, unit string) {
+	if l.x+panelWidth > gridWidth {
+		l.x, l.y = 0, l.y+panelHeight
+	}
+
+	l.id++
+	l.panels = append(l.panels, panel{
+		ID:          l.id,
+		Type:        "timeseries",
+		Title:       title,
+		Description: description,
+		GridPos:     gridPos{X: l.x, Y: l.y, W: panelWidth, H: panelHeight},
+		Datasource:  map[string]any{"type": "prometheus", "uid": "${datasource}"},
+		Targets:     []target{{RefID: "A", Expr: expr, LegendFormat: legend}},
+		FieldConfig: map[string]any{"defaults": map[string]any{"unit": unit}},
+	})
+
+	l.x += panelWidth
+}
+
+// variables returns the template variables of the dashboard.
+func variables() []map[string]any {
+	source := metrics.EphemeralStorageUsageBytes.FQName()
+
+	vars := []map[string]any{
+		{"name": "datasource", "label": "Data source", "type": "datasource", "query": "prometheus"},
+	}
+
+	for _, name := range []string{"exported_node", "exported_namespace", "exported_pod"} {
+		vars = append(vars, map[string]any{
+			"name":       strings.TrimPrefix(name, "exported_"),
+			"label":      strings.TrimPrefix(name, "exported_"),
+			"type":       "query",
+			"datasource": map[string]any{"type": "prometheus", "uid": "${datasource}"},
+			"query":      fmt.Sprintf("label_values(%s, %s)", source, name),
+			"refresh":    2,
+			"multi":      true,
+			"includeAll": true,
+			"allValue":   ".*",
+		})
+	}
+
+	return vars
+}
+
+// filter returns the label selector that applies the template variables to a metric.
+func filter(labels []string) string {
+	var matchers []string
+	for _, label := range labels {
+		switch label {
+		case "exported_node", "exported_namespace", "exported_pod":
+			matchers = append(matchers, fmt.Sprintf(`%s=~"$%s"`, label, strings.TrimPrefix(label, "exported_")))
+		}
+	}
+
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// legendFormat returns the legend of the series, made of their label values.
+func legendFormat(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label == "exported_node" {
+			continue
+		}
+
+		parts = append(parts, "{{"+label+"}}")
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// topk limits the expression to the largest series.
+func topk(k int, expr string) string {
+	return fmt.Sprintf("topk(%d, %s)", k, expr)
+}
@@ -0,0 +1,70 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// group holds the storage metric definitions of a subsystem, paired by their role.
+type group struct {
+	Subsystem     string
+	Used          *metrics.Definition
+	Available     *metrics.Definition
+	Capacity      *metrics.Definition
+	InodesUsed    *metrics.Definition
+	InodesTotal   *metrics.Definition
+	BytesPerInode *metrics.Definition
+}
+
+// Title returns a human readable name of the subsystem, e.g. Container Rootfs.
+func (g *group) Title() string {
+	words := strings.Split(g.Subsystem, "_")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Labels returns the label names of the subsystem metrics.
+func (g *group) Labels() []string {
+	return g.Used.Labels
+}
+
+// groups pairs the metric definitions of every storage subsystem. Memory is not a
+// storage subsystem, so it is left out.
+func groups() []*group {
+	var list []*group
+	index := make(map[string]*group)
+
+	for _, def := range metrics.Definitions {
+		if def.Subsystem == "" || def.Subsystem == metrics.SSContainerMemory {
+			continue
+		}
+
+		g, ok := index[def.Subsystem]
+		if !ok {
+			g = &group{Subsystem: def.Subsystem}
+			index[def.Subsystem] = g
+			list = append(list, g)
+		}
+
+		switch def.Name {
+		case "used_bytes", "usage_bytes":
+			g.Used = def
+		case "available_bytes":
+			g.Available = def
+		case "capacity_bytes":
+			g.Capacity = def
+		case "inodes_used":
+			g.InodesUsed = def
+		case "inodes_total":
+			g.InodesTotal = def
+		case "bytes_per_inode":
+			g.BytesPerInode = def
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,180 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RulesOptions holds the options of the generated PrometheusRule.
+type RulesOptions struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	// NearLimit is the used fraction of the capacity that triggers the near-limit alerts.
+	NearLimit float64
+	// FillWindow is the range used to predict the fill rate, e.g. 6h.
+	FillWindow string
+	// FillHorizon is how far the fill rate is predicted, in hours.
+	FillHorizon int
+}
+
+// prometheusRule is a monitoring.coreos.com/v1 PrometheusRule object.
+type prometheusRule struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   ruleMetadata   `yaml:"metadata"`
+	Spec       prometheusSpec `yaml:"spec"`
+}
+
+type ruleMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type prometheusSpec struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Rules writes a PrometheusRule manifest with the alerts of every storage subsystem.
+func Rules(w io.Writer, opts RulesOptions) error {
+	obj := prometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata: ruleMetadata{
+			Name:      opts.Name,
+			Namespace: opts.Namespace,
+			Labels:    opts.Labels,
+		},
+		Spec: prometheusSpec{
+			Groups: []ruleGroup{
+				{Name: "localsight.exporter", Rules: exporterRules()},
+				{Name: "localsight.storage", Rules: storageRules(opts)},
+			},
+		},
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(obj); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// exporterRules returns the alerts on the exporter itself.
+func exporterRules() []rule {
+	status := metrics.APIStatus.FQName()
+
+	return []rule{
+		{
+			Alert: "LocalSightSummaryAPIDown",
+			Expr:  fmt.Sprintf("%s == 0", status),
+			For:   "5m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "LocalSight cannot read the summary API of node {{ $labels.exported_node }}",
+				"description": metrics.APIStatus.Help,
+			},
+		},
+		{
+			Alert: "LocalSightExporterDown",
+			Expr:  fmt.Sprintf("absent(%s)", status),
+			For:   "10m",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary": "LocalSight is not exporting any metrics",
+			},
+		},
+	}
+}
+
+// storageRules returns the fill-rate, near-limit and inode exhaustion alerts of each subsystem.
+func storageRules(opts RulesOptions) []rule {
+	var rules []rule
+
+	for _, g := range groups() {
+		name := strings.ReplaceAll(g.Title(), " ", "")
+		where := describe(g.Labels())
+
+		if g.Used != nil && g.Capacity != nil {
+			ratio := fmt.Sprintf("%s / %s", g.Used.FQName(), g.Capacity.FQName())
+
+			rules = append(rules, rule{
+				Alert:  "LocalSight" + name + "NearLimit",
+				Expr:   fmt.Sprintf("(%s) > %g and %s > 0", ratio, opts.NearLimit, g.Capacity.FQName()),
+				For:    "15m",
+				Labels: map[string]string{"severity": "warning"},
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("%s of %s is above %g%% of its capacity", g.Title(), where, opts.NearLimit*100),
+				},
+			}, rule{
+				Alert: "LocalSight" + name + "FillingUp",
+				Expr: fmt.Sprintf(
+					"predict_linear(%s[%s], %d * 3600) > %s and %s > 0",
+					g.Used.FQName(), opts.FillWindow, opts.FillHorizon, g.Capacity.FQName(), g.Capacity.FQName(),
+				),
+				For:    "1h",
+				Labels: map[string]string{"severity": "warning"},
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("%s of %s is predicted to fill up within %d hours", g.Title(), where, opts.FillHorizon),
+				},
+			})
+		}
+
+		if g.InodesUsed != nil && g.InodesTotal != nil {
+			rules = append(rules, rule{
+				Alert: "LocalSight" + name + "InodesExhausting",
+				Expr: fmt.Sprintf(
+					"(%s / %s) > %g and %s > 0",
+					g.InodesUsed.FQName(), g.InodesTotal.FQName(), opts.NearLimit, g.InodesTotal.FQName(),
+				),
+				For:    "15m",
+				Labels: map[string]string{"severity": "warning"},
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("%s of %s uses more than %g%% of its inodes", g.Title(), where, opts.NearLimit*100),
+				},
+			})
+		}
+	}
+
+	return rules
+}
+
+// describe returns the alert annotation template that names the series.
+func describe(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label == "exported_node" {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("{{ $labels.%s }}", label))
+	}
+
+	return strings.Join(parts, "/") + " on {{ $labels.exported_node }}"
+}
@@ -0,0 +1,89 @@
+package history
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// labelParams are the query parameters that filter the series by label.
+var labelParams = []string{"pod", "namespace", "node", "container", "volume"}
+
+// response is the body of the history API.
+type response struct {
+	Metric string   `json:"metric"`
+	Series []Series `json:"series"`
+}
+
+// Register adds the history API to the mux.
+func (s *Store) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/history", s.handle)
+}
+
+// handle responds with the history of a metric, e.g.
+// /api/v1/history?metric=ephemeral_storage_used_bytes&pod=web-0&namespace=default&since=15m
+func (s *Store) handle(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	def := lookup(query.Get("metric"))
+	if def == nil {
+		writeError(w, fmt.Errorf("unknown metric %q, use the name of an exported metric, with or without the %s_ prefix", query.Get("metric"), metrics.NS))
+		return
+	}
+
+	if !tracked[def] {
+		writeError(w, fmt.Errorf("metric %q has no history, only the pod ephemeral storage metrics are kept", query.Get("metric")))
+		return
+	}
+
+	from := time.Time{}
+	if since := query.Get("since"); since != "" {
+		d, err := time.ParseDuration(since)
+		if err != nil {
+			writeError(w, fmt.Errorf("invalid since: %w", err))
+			return
+		}
+
+		from = time.Now().Add(-d)
+	}
+
+	matchers := make(map[string]string)
+	for _, name := range labelParams {
+		if value := query.Get(name); value != "" {
+			matchers[name] = value
+		}
+	}
+
+	list := s.Query(def, matchers, from)
+	slices.SortFunc(list, func(a, b Series) int {
+		return cmp.Compare(fmt.Sprint(a.Labels), fmt.Sprint(b.Labels))
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response{Metric: def.FQName(), Series: list})
+}
+
+// lookup returns the definition of the metric name, with or without the namespace prefix.
+func lookup(name string) *metrics.Definition {
+	name = strings.TrimPrefix(name, metrics.NS+"_")
+	for _, def := range metrics.Definitions {
+		if strings.TrimPrefix(def.FQName(), metrics.NS+"_") == name {
+			return def
+		}
+	}
+
+	return nil
+}
+
+// writeError responds with a bad request and the error message.
+func writeError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,59 @@
+package history
+
+import "time"
+
+// Point is the value of a series at a point in time.
+type Point struct {
+	Timestamp time.Time `json:"t"`
+	Value     float64   `json:"v"`
+}
+
+// ring is a bounded buffer of points that overwrites the oldest point when full. The
+// buffer grows with the points, so short lived series do not hold a full retention.
+type ring struct {
+	points   []Point
+	start    int
+	size     int
+	capacity int
+}
+
+// newRing creates a ring that holds up to the given number of points.
+func newRing(capacity int) *ring {
+	return &ring{capacity: capacity}
+}
+
+// add appends a point, dropping the oldest one when the ring is full.
+func (r *ring) add(p Point) {
+	// the start only moves once the ring is full, so the points are in order until then
+	if r.size < r.capacity {
+		r.points = append(r.points, p)
+		r.size++
+
+		return
+	}
+
+	r.points[r.start] = p
+	r.start = (r.start + 1) % len(r.points)
+}
+
+// last returns the newest point, or false if the ring is empty.
+func (r *ring) last() (Point, bool) {
+	if r.size == 0 {
+		return Point{}, false
+	}
+
+	return r.points[(r.start+r.size-1)%len(r.points)], true
+}
+
+// since returns a copy of the points newer than the time, oldest first.
+func (r *ring) since(from time.Time) []Point {
+	list := make([]Point, 0, r.size)
+	for i := range r.size {
+		p := r.points[(r.start+i)%len(r.points)]
+		if !p.Timestamp.Before(from) {
+			list = append(list, p)
+		}
+	}
+
+	return list
+}
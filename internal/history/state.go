@@ -0,0 +1,64 @@
+package history
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// seriesState is the checkpointed history of a series.
+type seriesState struct {
+	Metric string   `json:"metric"`
+	Labels []string `json:"labels"`
+	Points []Point  `json:"points"`
+}
+
+// MarshalState returns the points of every series for the checkpoint.
+func (s *Store) MarshalState() ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	list := make([]seriesState, 0, len(s.series))
+	for _, ser := range s.series {
+		list = append(list, seriesState{
+			Metric: ser.definition.FQName(),
+			Labels: ser.labels,
+			Points: ser.points.since(time.Time{}),
+		})
+	}
+
+	return json.Marshal(list)
+}
+
+// RestoreState loads the series of a checkpoint, dropping the points older than the
+// retention period and the metrics that no longer exist.
+func (s *Store) RestoreState(data []byte) error {
+	var list []seriesState
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	from := time.Now().Add(-s.retention)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, state := range list {
+		def := lookup(state.Metric)
+		if def == nil || len(state.Labels) != len(def.Labels) || s.full() {
+			continue
+		}
+
+		ser := &series{definition: def, labels: state.Labels, points: newRing(s.capacity)}
+		for _, p := range state.Points {
+			if !p.Timestamp.Before(from) {
+				ser.points.add(p)
+			}
+		}
+
+		if ser.points.size > 0 {
+			s.series[seriesKey(def, state.Labels)] = ser
+		}
+	}
+
+	return nil
+}
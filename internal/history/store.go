@@ -0,0 +1,174 @@
+package history
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// series is the history of a metric with a set of label values.
+type series struct {
+	definition *metrics.Definition
+	labels     []string
+	points     *ring
+}
+
+// Series is the result of a history query.
+type Series struct {
+	Labels map[string]string `json:"labels"`
+	Points []Point           `json:"points"`
+}
+
+// tracked are the metrics kept in the history, the pod level ephemeral storage and inode
+// series. The container and volume series multiply the memory of the history with little
+// use for the UI and the history API.
+var tracked = map[*metrics.Definition]bool{
+	metrics.EphemeralStorageUsageBytes:     true,
+	metrics.EphemeralStorageAvailableBytes: true,
+	metrics.EphemeralStorageCapacityBytes:  true,
+	metrics.EphemeralStorageInodesUsed:     true,
+	metrics.EphemeralStorageInodesFree:     true,
+	metrics.EphemeralStorageInodes:         true,
+}
+
+// Store keeps the recent samples of the tracked series in memory, in a ring buffer per
+// series, up to a maximum number of series. It implements the sink interface.
+type Store struct {
+	retention time.Duration
+	capacity  int
+	maxSeries int
+
+	lock   sync.RWMutex
+	series map[string]*series
+	pruned time.Time
+}
+
+// NewStore creates a store that keeps the samples of the retention period, collected
+// on the interval, of at most the given number of series. Zero does not limit the series.
+func NewStore(retention, interval time.Duration, maxSeries int) *Store {
+	return &Store{
+		retention: retention,
+		capacity:  max(int((retention+interval-1)/interval), 1),
+		maxSeries: maxSeries,
+		series:    make(map[string]*series),
+	}
+}
+
+// Name returns the name of the sink.
+func (s *Store) Name() string {
+	return "history"
+}
+
+// Write appends the samples of the snapshot to their series, and drops the series that
+// disappeared, e.g. of deleted pods or of nodes that left the cluster.
+func (s *Store) Write(_ context.Context, snapshot *metrics.Snapshot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, sample := range snapshot.Samples {
+		if !tracked[sample.Definition] {
+			continue
+		}
+
+		key := seriesKey(sample.Definition, sample.LabelValues)
+
+		ser, ok := s.series[key]
+		if !ok {
+			// the new series are dropped once the store is full
+			if s.full() {
+				continue
+			}
+
+			ser = &series{
+				definition: sample.Definition,
+				labels:     sample.LabelValues,
+				points:     newRing(s.capacity),
+			}
+			s.series[key] = ser
+		}
+
+		ser.points.add(Point{Timestamp: snapshot.Timestamp, Value: sample.Value})
+	}
+
+	// drop the series that were not updated during the retention period, at most
+	// once a minute as it goes over every series
+	if snapshot.Timestamp.Sub(s.pruned) < time.Minute {
+		return nil
+	}
+
+	for key, ser := range s.series {
+		if last, ok := ser.points.last(); ok && snapshot.Timestamp.Sub(last.Timestamp) > s.retention {
+			delete(s.series, key)
+		}
+	}
+
+	s.pruned = snapshot.Timestamp
+
+	return nil
+}
+
+// full returns true if the store holds the maximum number of series.
+func (s *Store) full() bool {
+	return s.maxSeries > 0 && len(s.series) >= s.maxSeries
+}
+
+// Points returns the points of a series since the time, oldest first.
+func (s *Store) Points(def *metrics.Definition, labels []string, from time.Time) []Point {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ser, ok := s.series[seriesKey(def, labels)]
+	if !ok {
+		return nil
+	}
+
+	return ser.points.since(from)
+}
+
+// Query returns the points since the time of the series of the metric whose labels
+// match the matchers. The matchers are keyed by the label names without the exported_ prefix.
+func (s *Store) Query(def *metrics.Definition, matchers map[string]string, from time.Time) []Series {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var list []Series
+	for _, ser := range s.series {
+		if ser.definition != def {
+			continue
+		}
+
+		labels := make(map[string]string, len(ser.labels))
+		for i, name := range def.Labels {
+			labels[strings.TrimPrefix(name, "exported_")] = ser.labels[i]
+		}
+
+		if !matches(labels, matchers) {
+			continue
+		}
+
+		if points := ser.points.since(from); len(points) > 0 {
+			list = append(list, Series{Labels: labels, Points: points})
+		}
+	}
+
+	return list
+}
+
+// matches returns true if the labels have the values of every matcher.
+func matches(labels, matchers map[string]string) bool {
+	for name, value := range matchers {
+		if labels[name] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// seriesKey returns the key of a series.
+func seriesKey(def *metrics.Definition, labels []string) string {
+	return def.FQName() + "\x00" + strings.Join(labels, "\x00")
+}
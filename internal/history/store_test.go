@@ -0,0 +1,64 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+func TestStoreWrite(t *testing.T) {
+	store := NewStore(time.Hour, time.Minute, 4)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range 3 {
+		snapshot := metrics.NewSnapshot("node")
+		snapshot.Timestamp = start.Add(time.Duration(i) * time.Minute)
+
+		// three pods with three tracked series each, and the container series
+		for pod := range 3 {
+			name := fmt.Sprintf("pod-%d", pod)
+			snapshot.SetEphemeralStorageValues(name, "default", "node", float64(i), 0, 100)
+			snapshot.SetContainerRootfsValues(name, "default", "node", "app", float64(i), 0, 100)
+		}
+
+		if err := store.Write(context.Background(), snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(store.series) != 4 {
+		t.Fatalf("expected 4 series, got %d", len(store.series))
+	}
+
+	for _, ser := range store.series {
+		if !tracked[ser.definition] {
+			t.Errorf("series of %s is not tracked", ser.definition.FQName())
+		}
+
+		// the rings grow with their points
+		if ser.points.size != 3 || len(ser.points.points) != 3 {
+			t.Errorf("expected 3 points, got %d in a buffer of %d", ser.points.size, len(ser.points.points))
+		}
+	}
+}
+
+func TestRing(t *testing.T) {
+	r := newRing(3)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		r.add(Point{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: float64(i)})
+	}
+
+	points := r.since(time.Time{})
+	if len(points) != 3 || points[0].Value != 2 || points[2].Value != 4 {
+		t.Errorf("expected the points 2 to 4, got %v", points)
+	}
+
+	if last, ok := r.last(); !ok || last.Value != 4 {
+		t.Errorf("expected the last point 4, got %v", last)
+	}
+}
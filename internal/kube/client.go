@@ -0,0 +1,181 @@
+package kube
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// constant values for the in-cluster service account files
+const (
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// Client is a minimal Kubernetes API server client that authenticates using the
+// pod service account.
+type Client struct {
+	host      string
+	tokenFile string
+	http      *http.Client
+}
+
+// NewInCluster creates a client using the service account mounted into the pod.
+// If host is empty, the API server address is taken from the environment variables
+// that Kubernetes sets for every pod.
+func NewInCluster(host string) (*Client, error) {
+	if host == "" {
+		h, p := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if h == "" || p == "" {
+			return nil, fmt.Errorf("not running in a cluster: KUBERNETES_SERVICE_HOST or KUBERNETES_SERVICE_PORT is empty")
+		}
+
+		host = "https://" + net.JoinHostPort(h, p)
+	}
+
+	// load the cluster CA certificate
+	ca, err := os.ReadFile(serviceAccountCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to parse service account CA")
+	}
+
+	return NewClient(host, serviceAccountToken, &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}), nil
+}
+
+// NewClient creates a client of the API server at host, which sends the token of the
+// token file with every request.
+func NewClient(host, tokenFile string, client *http.Client) *Client {
+	return &Client{
+		host:      strings.TrimSuffix(host, "/"),
+		tokenFile: tokenFile,
+		http:      client,
+	}
+}
+
+// Host returns the API server address.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// Do sends a request to the given API path with the service account token.
+// The token is read on every call, since the kubelet rotates it periodically.
+func (c *Client) Do(method, path string, body []byte, contentType string) (*http.Response, error) {
+	token, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.host+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return c.http.Do(req)
+}
+
+// Get sends a GET request to the given API path. A non-successful response is returned
+// as a StatusError, otherwise the caller must close the response body.
+func (c *Client) Get(path string) (*http.Response, error) {
+	resp, err := c.Do(http.MethodGet, path, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetJSON sends a GET request to the given API path and decodes the JSON response.
+func (c *Client) GetJSON(path string, out any) error {
+	return c.doJSON(http.MethodGet, path, nil, out)
+}
+
+// StatusError is returned when the API server responds with a non-successful status code.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.Code, e.Body)
+}
+
+// IsNotFound returns true if the error is a not found response of the API server.
+func IsNotFound(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.Code == http.StatusNotFound
+}
+
+// doJSON sends the input object as JSON and decodes the response into the output object.
+func (c *Client) doJSON(method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+
+		body = data
+	}
+
+	resp, err := c.Do(method, path, body, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// checkStatus returns a StatusError and closes the body of a non-successful response.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	return &StatusError{Code: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+}
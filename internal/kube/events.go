@@ -0,0 +1,79 @@
+package kube
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Event is a core/v1 Event object.
+type Event struct {
+	APIVersion         string          `json:"apiVersion"`
+	Kind               string          `json:"kind"`
+	Metadata           EventMetadata   `json:"metadata"`
+	InvolvedObject     ObjectReference `json:"involvedObject"`
+	Reason             string          `json:"reason"`
+	Message            string          `json:"message"`
+	Type               string          `json:"type"`
+	Count              int             `json:"count"`
+	FirstTimestamp     string          `json:"firstTimestamp"`
+	LastTimestamp      string          `json:"lastTimestamp"`
+	Source             EventSource     `json:"source"`
+	ReportingComponent string          `json:"reportingComponent"`
+	ReportingInstance  string          `json:"reportingInstance"`
+}
+
+// EventMetadata holds the object metadata of an event.
+type EventMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ObjectReference points to the object an event is about.
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	UID        string `json:"uid,omitempty"`
+}
+
+// EventSource is the component that reported an event.
+type EventSource struct {
+	Component string `json:"component"`
+	Host      string `json:"host,omitempty"`
+}
+
+// NewPodWarning creates a warning event about a pod.
+func NewPodWarning(pod ObjectReference, reason, message, component, host string, now time.Time) *Event {
+	ts := now.UTC().Format(time.RFC3339)
+
+	pod.APIVersion = "v1"
+	pod.Kind = "Pod"
+
+	return &Event{
+		APIVersion: "v1",
+		Kind:       "Event",
+		Metadata: EventMetadata{
+			Name:      fmt.Sprintf("%s.%x", pod.Name, now.UnixNano()),
+			Namespace: pod.Namespace,
+		},
+		InvolvedObject:     pod,
+		Reason:             reason,
+		Message:            message,
+		Type:               "Warning",
+		Count:              1,
+		FirstTimestamp:     ts,
+		LastTimestamp:      ts,
+		Source:             EventSource{Component: component, Host: host},
+		ReportingComponent: component,
+		ReportingInstance:  host,
+	}
+}
+
+// CreateEvent creates an event in the namespace of the event.
+func (c *Client) CreateEvent(event *Event) error {
+	path := fmt.Sprintf("/api/v1/namespaces/%s/events", url.PathEscape(event.Metadata.Namespace))
+	return c.doJSON(http.MethodPost, path, event, nil)
+}
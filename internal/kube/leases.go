@@ -0,0 +1,112 @@
+package kube
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// leaseTimeFormat is the micro time format used by the lease spec.
+const leaseTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// Lease is the part of a coordination.k8s.io/v1 Lease object that we need.
+type Lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   LeaseMetadata `json:"metadata"`
+	Spec       LeaseSpec     `json:"spec"`
+}
+
+// LeaseMetadata holds the object metadata of a lease.
+type LeaseMetadata struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+}
+
+// LeaseSpec holds the holder and timing information of a lease.
+type LeaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+}
+
+// leaseList is the response of listing leases.
+type leaseList struct {
+	Items []Lease `json:"items"`
+}
+
+// NewLease creates a lease object with the given name and labels.
+func NewLease(namespace, name string, labels map[string]string) *Lease {
+	return &Lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata: LeaseMetadata{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+// Renew sets the holder and the renew time of the lease.
+func (l *Lease) Renew(holder string, duration time.Duration, now time.Time) {
+	l.Spec.HolderIdentity = holder
+	l.Spec.LeaseDurationSeconds = int(duration.Seconds())
+	l.Spec.RenewTime = now.UTC().Format(leaseTimeFormat)
+}
+
+// Expired returns true if the lease has not been renewed within its duration.
+func (l *Lease) Expired(now time.Time) bool {
+	renew, err := time.Parse(time.RFC3339Nano, l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+
+	return renew.Add(time.Duration(l.Spec.LeaseDurationSeconds) * time.Second).Before(now)
+}
+
+// leasesPath returns the API path of the leases in a namespace.
+func leasesPath(namespace string) string {
+	return fmt.Sprintf("/apis/coordination.k8s.io/v1/namespaces/%s/leases", url.PathEscape(namespace))
+}
+
+// GetLease returns the lease with the given name.
+func (c *Client) GetLease(namespace, name string) (*Lease, error) {
+	var lease Lease
+	if err := c.doJSON(http.MethodGet, leasesPath(namespace)+"/"+url.PathEscape(name), nil, &lease); err != nil {
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+// CreateLease creates a new lease.
+func (c *Client) CreateLease(lease *Lease) error {
+	return c.doJSON(http.MethodPost, leasesPath(lease.Metadata.Namespace), lease, nil)
+}
+
+// UpdateLease replaces an existing lease. The resource version of the lease must be set.
+func (c *Client) UpdateLease(lease *Lease) error {
+	path := leasesPath(lease.Metadata.Namespace) + "/" + url.PathEscape(lease.Metadata.Name)
+	return c.doJSON(http.MethodPut, path, lease, nil)
+}
+
+// DeleteLease deletes the lease with the given name.
+func (c *Client) DeleteLease(namespace, name string) error {
+	return c.doJSON(http.MethodDelete, leasesPath(namespace)+"/"+url.PathEscape(name), nil, nil)
+}
+
+// ListLeases returns the leases in a namespace that match the label selector.
+func (c *Client) ListLeases(namespace, selector string) ([]Lease, error) {
+	path := leasesPath(namespace) + "?labelSelector=" + url.QueryEscape(selector)
+
+	var list leaseList
+	if err := c.doJSON(http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
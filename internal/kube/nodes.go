@@ -0,0 +1,27 @@
+package kube
+
+import "fmt"
+
+// nodeList is the part of the API server node list response that we need.
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	} `json:"items"`
+}
+
+// ListNodes returns the names of all nodes in the cluster.
+func (c *Client) ListNodes() ([]string, error) {
+	var list nodeList
+	if err := c.GetJSON("/api/v1/nodes", &list); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Metadata.Name)
+	}
+
+	return names, nil
+}
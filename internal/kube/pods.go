@@ -0,0 +1,61 @@
+package kube
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Pod is the part of a pod object that we need.
+type Pod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		UID       string `json:"uid"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers []struct {
+			Name      string `json:"name"`
+			Resources struct {
+				Limits map[string]string `json:"limits"`
+			} `json:"resources"`
+		} `json:"containers"`
+	} `json:"spec"`
+}
+
+// podList is the response of listing pods.
+type podList struct {
+	Items []Pod `json:"items"`
+}
+
+// Limit returns the sum of the container limits of a resource, or zero if a
+// container has no limit for it, since the pod is then unbounded.
+func (p *Pod) Limit(resource string) int64 {
+	var total int64
+	for _, container := range p.Spec.Containers {
+		value, ok := container.Resources.Limits[resource]
+		if !ok {
+			return 0
+		}
+
+		limit, err := ParseQuantity(value)
+		if err != nil {
+			return 0
+		}
+
+		total += limit
+	}
+
+	return total
+}
+
+// ListNodePods returns the pods scheduled on the given node.
+func (c *Client) ListNodePods(node string) ([]Pod, error) {
+	path := "/api/v1/pods?fieldSelector=" + url.QueryEscape("spec.nodeName="+node)
+
+	var list podList
+	if err := c.doJSON(http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
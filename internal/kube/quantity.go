@@ -0,0 +1,65 @@
+package kube
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// quantitySuffixes maps the Kubernetes quantity suffixes to their multipliers.
+var quantitySuffixes = map[string]float64{
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+	"m":  1e-3,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"E":  1e18,
+}
+
+// ParseQuantity parses a Kubernetes resource quantity, e.g. 512Mi or 1G, into a number of units.
+// The storage quantities are sizes, so negative and out of range quantities are rejected.
+func ParseQuantity(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+
+	// binary suffixes are two characters long, decimal suffixes are one
+	number, multiplier := value, 1.0
+	for _, size := range []int{2, 1} {
+		if len(value) <= size {
+			continue
+		}
+
+		if m, ok := quantitySuffixes[value[len(value)-size:]]; ok {
+			number, multiplier = value[:len(value)-size], m
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q: %w", value, err)
+	}
+
+	// the float parser also accepts the infinities and NaN
+	size := math.Ceil(n * multiplier)
+	switch {
+	case math.IsNaN(size) || math.IsInf(size, 0):
+		return 0, fmt.Errorf("invalid quantity %q", value)
+	case size < 0:
+		return 0, fmt.Errorf("negative quantity %q", value)
+	case size >= math.MaxInt64:
+		return 0, fmt.Errorf("quantity %q is out of range", value)
+	}
+
+	return int64(size), nil
+}
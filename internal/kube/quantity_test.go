@@ -0,0 +1,52 @@
+package kube
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		err   bool
+	}{
+		{value: "0", want: 0},
+		{value: "1024", want: 1024},
+		{value: " 512Mi ", want: 512 << 20},
+		{value: "1.5Gi", want: 3 << 29},
+		{value: "1G", want: 1e9},
+		{value: "100k", want: 1e5},
+		{value: "7Ei", want: 7 << 60},
+		{value: "1e3", want: 1000},
+		// the milli units of a byte are rounded up
+		{value: "1500m", want: 2},
+		{value: "", err: true},
+		{value: "Mi", err: true},
+		{value: "12Xi", err: true},
+		{value: "-1", err: true},
+		{value: "-512Mi", err: true},
+		{value: "8Ei", err: true},
+		{value: "9Ei", err: true},
+		{value: "1e30", err: true},
+		{value: "Inf", err: true},
+		{value: "NaN", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQuantity(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %d", tt.value, got)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%q: expected %d, got %d", tt.value, tt.want, got)
+		}
+	}
+}
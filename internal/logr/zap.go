@@ -23,7 +23,8 @@ func NewZapLogger(debug bool, json bool) *zap.Logger {
 		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
 	}
 
-	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), level)
+	// the logs go to stderr, so they do not mix with the records of the stdout outputs
+	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), level)
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 
 	return logger
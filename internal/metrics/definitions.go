@@ -0,0 +1,126 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// label sets of the metrics
+var (
+	nodeLabels      = []string{"exported_node"}
+	podLabels       = []string{"exported_pod", "exported_namespace", "exported_node"}
+	containerLabels = []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}
+	volumeLabels    = []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}
+)
+
+// Definition describes a single gauge exported by LocalSight.
+type Definition struct {
+	Subsystem string
+	Name      string
+	Help      string
+	Labels    []string
+
+	desc *prometheus.Desc
+}
+
+// newDefinition creates a new metric definition with its Prometheus descriptor.
+func newDefinition(subsystem, name, help string, labels []string) *Definition {
+	return &Definition{
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+		Labels:    labels,
+		desc:      prometheus.NewDesc(prometheus.BuildFQName(NS, subsystem, name), help, labels, nil),
+	}
+}
+
+// FQName returns the fully qualified name of the metric.
+func (d *Definition) FQName() string {
+	return prometheus.BuildFQName(NS, d.Subsystem, d.Name)
+}
+
+// metric definitions of the exporter
+var (
+	// API Metrics
+	APIStatus  = newDefinition("", "api_status", "Summary API status on the target node (0 is down, 1 is up)", nodeLabels)
+	APILatency = newDefinition("", "api_latency", "Summary API response time in seconds", nodeLabels)
+
+	// Ephemeral Storage
+	EphemeralStorageAvailableBytes = newDefinition(SSEphemeralStorage, "available_bytes", "Ephemeral storage available space in bytes", podLabels)
+	EphemeralStorageCapacityBytes  = newDefinition(SSEphemeralStorage, "capacity_bytes", "Ephemeral storage space capacity in bytes", podLabels)
+	EphemeralStorageUsageBytes     = newDefinition(SSEphemeralStorage, "used_bytes", "Ephemeral storage used space in bytes", podLabels)
+	EphemeralStorageInodes         = newDefinition(SSEphemeralStorage, "inodes_total", "Ephemeral storage number of total inodes", podLabels)
+	EphemeralStorageInodesFree     = newDefinition(SSEphemeralStorage, "inodes_free", "Ephemeral storage number of free inodes", podLabels)
+	EphemeralStorageInodesUsed     = newDefinition(SSEphemeralStorage, "inodes_used", "Ephemeral storage number of used inodes", podLabels)
+	EphemeralStorageBytesPerInode  = newDefinition(SSEphemeralStorage, "bytes_per_inode", "Ephemeral storage average used bytes per used inode", podLabels)
+	EphemeralStorageInodesRatio    = newDefinition(SSEphemeralStorage, "inodes_used_ratio", "Ephemeral storage used inodes as a fraction of the total inodes", podLabels)
+	EphemeralStorageUsedAnomaly    = newDefinition(SSEphemeralStorage, "used_bytes_anomaly_score", "Deviations of the ephemeral storage growth rate from its moving average", podLabels)
+	EphemeralStorageInodesAnomaly  = newDefinition(SSEphemeralStorage, "inodes_used_anomaly_score", "Deviations of the ephemeral storage inodes growth rate from its moving average", podLabels)
+
+	// Container Memory
+	ContainerMemoryAvailableBytes = newDefinition(SSContainerMemory, "available_bytes", "Container memory available space in bytes", containerLabels)
+	ContainerMemoryCapacityBytes  = newDefinition(SSContainerMemory, "capacity_bytes", "Container memory capacity in bytes", containerLabels)
+	ContainerMemoryUsageBytes     = newDefinition(SSContainerMemory, "usage_bytes", "Container memory used space in bytes", containerLabels)
+
+	// Container RootFS
+	ContainerRootfsAvailableBytes = newDefinition(SSContainerRootFS, "available_bytes", "Container root file system available space in bytes", containerLabels)
+	ContainerRootfsCapacityBytes  = newDefinition(SSContainerRootFS, "capacity_bytes", "Container root file system capacity in bytes", containerLabels)
+	ContainerRootfsUsageBytes     = newDefinition(SSContainerRootFS, "usage_bytes", "Container root file system used space in bytes", containerLabels)
+	ContainerRootfsInodes         = newDefinition(SSContainerRootFS, "inodes_total", "Container root file system total number of inodes", containerLabels)
+	ContainerRootfsInodesFree     = newDefinition(SSContainerRootFS, "inodes_free", "Container root file system number of free inodes", containerLabels)
+	ContainerRootfsInodesUsed     = newDefinition(SSContainerRootFS, "inodes_used", "Container root file system number of used inodes", containerLabels)
+
+	// Container Logs
+	ContainerLogsAvailableBytes = newDefinition(SSContainerLogs, "available_bytes", "Container logs space available in bytes", containerLabels)
+	ContainerLogsCapacityBytes  = newDefinition(SSContainerLogs, "capacity_bytes", "Container logs capacity in bytes", containerLabels)
+	ContainerLogsUsageBytes     = newDefinition(SSContainerLogs, "usage_bytes", "Container logs used space in bytes", containerLabels)
+	ContainerLogsInodes         = newDefinition(SSContainerLogs, "inodes_total", "Container logs total number of inodes", containerLabels)
+	ContainerLogsInodesFree     = newDefinition(SSContainerLogs, "inodes_free", "Container logs number of free inodes", containerLabels)
+	ContainerLogsInodesUsed     = newDefinition(SSContainerLogs, "inodes_used", "Container logs number of used inodes", containerLabels)
+
+	// Pod Volume
+	PodVolumeAvailableBytes = newDefinition(SSPodVolume, "available_bytes", "Pod volume space available in bytes", volumeLabels)
+	PodVolumeCapacityBytes  = newDefinition(SSPodVolume, "capacity_bytes", "Pod volume capacity in bytes", volumeLabels)
+	PodVolumeUsageBytes     = newDefinition(SSPodVolume, "usage_bytes", "Pod volume used space in bytes", volumeLabels)
+	PodVolumeInodes         = newDefinition(SSPodVolume, "inodes_total", "Pod volume total number of inodes", volumeLabels)
+	PodVolumeInodesFree     = newDefinition(SSPodVolume, "inodes_free", "Pod volume number of free inodes", volumeLabels)
+	PodVolumeInodesUsed     = newDefinition(SSPodVolume, "inodes_used", "Pod volume number of used inodes", volumeLabels)
+	PodVolumeBytesPerInode  = newDefinition(SSPodVolume, "bytes_per_inode", "Pod volume average used bytes per used inode", volumeLabels)
+	PodVolumeInodesRatio    = newDefinition(SSPodVolume, "inodes_used_ratio", "Pod volume used inodes as a fraction of the total inodes", volumeLabels)
+)
+
+// Definitions lists every metric exported by LocalSight.
+var Definitions = []*Definition{
+	APIStatus,
+	APILatency,
+	EphemeralStorageAvailableBytes,
+	EphemeralStorageCapacityBytes,
+	EphemeralStorageUsageBytes,
+	EphemeralStorageInodes,
+	EphemeralStorageInodesFree,
+	EphemeralStorageInodesUsed,
+	EphemeralStorageBytesPerInode,
+	EphemeralStorageInodesRatio,
+	EphemeralStorageUsedAnomaly,
+	EphemeralStorageInodesAnomaly,
+	ContainerMemoryAvailableBytes,
+	ContainerMemoryCapacityBytes,
+	ContainerMemoryUsageBytes,
+	ContainerRootfsAvailableBytes,
+	ContainerRootfsCapacityBytes,
+	ContainerRootfsUsageBytes,
+	ContainerRootfsInodes,
+	ContainerRootfsInodesFree,
+	ContainerRootfsInodesUsed,
+	ContainerLogsAvailableBytes,
+	ContainerLogsCapacityBytes,
+	ContainerLogsUsageBytes,
+	ContainerLogsInodes,
+	ContainerLogsInodesFree,
+	ContainerLogsInodesUsed,
+	PodVolumeAvailableBytes,
+	PodVolumeCapacityBytes,
+	PodVolumeUsageBytes,
+	PodVolumeInodes,
+	PodVolumeInodesFree,
+	PodVolumeInodesUsed,
+	PodVolumeBytesPerInode,
+	PodVolumeInodesRatio,
+}
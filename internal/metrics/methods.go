@@ -1,101 +1,128 @@
 package metrics
 
-// SetAPIValues sets the summary API status on the target node.
-func (m *Metrics) SetAPIStatus(node string, status int) {
-	m.apiStatus.WithLabelValues(node).Set(float64(status))
+// SetAPIStatus sets the summary API status on the target node.
+func (s *Snapshot) SetAPIStatus(node string, status int) {
+	s.add(APIStatus, float64(status), node)
 }
 
 // SetAPIValues sets the summary API metrics on the target node.
-func (m *Metrics) SetAPIValues(node string, latency float64) {
-	m.apiLatency.WithLabelValues(node).Set(latency)
+func (s *Snapshot) SetAPIValues(node string, latency float64) {
+	s.add(APILatency, latency, node)
 }
 
 // SetEphemeralStorageValues sets the ephemeral storage metrics for a specific pod, namespace, and node.
-func (m *Metrics) SetEphemeralStorageValues(
+func (s *Snapshot) SetEphemeralStorageValues(
 	pod, namespace, node string,
 	used, available, capacity float64,
 ) {
-	m.ephemeralStorageUsageBytes.WithLabelValues(pod, namespace, node).Set(used)
-	m.ephemeralStorageAvailableBytes.WithLabelValues(pod, namespace, node).Set(available)
-	m.ephemeralStorageCapacityBytes.WithLabelValues(pod, namespace, node).Set(capacity)
+	s.add(EphemeralStorageUsageBytes, used, pod, namespace, node)
+	s.add(EphemeralStorageAvailableBytes, available, pod, namespace, node)
+	s.add(EphemeralStorageCapacityBytes, capacity, pod, namespace, node)
 }
 
 // SetEphemeralStorageInodes sets the ephemeral storage inode metrics for a specific pod, namespace, and node.
-func (m *Metrics) SetEphemeralStorageInodes(
+func (s *Snapshot) SetEphemeralStorageInodes(
 	pod, namespace, node string,
 	used, available, capacity float64,
 ) {
-	m.ephemeralStorageInodesUsed.WithLabelValues(pod, namespace, node).Set(used)
-	m.ephemeralStorageInodesFree.WithLabelValues(pod, namespace, node).Set(available)
-	m.ephemeralStorageInodes.WithLabelValues(pod, namespace, node).Set(capacity)
+	s.add(EphemeralStorageInodesUsed, used, pod, namespace, node)
+	s.add(EphemeralStorageInodesFree, available, pod, namespace, node)
+	s.add(EphemeralStorageInodes, capacity, pod, namespace, node)
+}
+
+// SetEphemeralStorageInodeUsage sets the ephemeral storage bytes per inode and inode pressure for a specific pod, namespace, and node.
+func (s *Snapshot) SetEphemeralStorageInodeUsage(
+	pod, namespace, node string,
+	bytesPerInode, ratio float64,
+) {
+	s.add(EphemeralStorageBytesPerInode, bytesPerInode, pod, namespace, node)
+	s.add(EphemeralStorageInodesRatio, ratio, pod, namespace, node)
+}
+
+// SetEphemeralStorageAnomalyScores sets the ephemeral storage growth anomaly scores for a specific pod, namespace, and node.
+func (s *Snapshot) SetEphemeralStorageAnomalyScores(
+	pod, namespace, node string,
+	used, inodes float64,
+) {
+	s.add(EphemeralStorageUsedAnomaly, used, pod, namespace, node)
+	s.add(EphemeralStorageInodesAnomaly, inodes, pod, namespace, node)
 }
 
 // SetContainerMemoryValues sets the memory metrics for a specific container in a pod, namespace, and node.
-func (m *Metrics) SetContainerMemoryValues(
+func (s *Snapshot) SetContainerMemoryValues(
 	pod, namespace, node, container string,
 	used, available, capacity float64,
 ) {
-	m.containerMemoryUsageBytes.WithLabelValues(pod, namespace, node, container).Set(used)
-	m.containerMemoryAvailableBytes.WithLabelValues(pod, namespace, node, container).Set(available)
-	m.containerMemoryCapacityBytes.WithLabelValues(pod, namespace, node, container).Set(capacity)
+	s.add(ContainerMemoryUsageBytes, used, pod, namespace, node, container)
+	s.add(ContainerMemoryAvailableBytes, available, pod, namespace, node, container)
+	s.add(ContainerMemoryCapacityBytes, capacity, pod, namespace, node, container)
 }
 
 // SetContainerRootfsValues sets the root filesystem metrics for a specific container in a pod, namespace, and node.
-func (m *Metrics) SetContainerRootfsValues(
+func (s *Snapshot) SetContainerRootfsValues(
 	pod, namespace, node, container string,
 	used, available, capacity float64,
 ) {
-	m.containerRootfsUsageBytes.WithLabelValues(pod, namespace, node, container).Set(used)
-	m.containerRootfsAvailableBytes.WithLabelValues(pod, namespace, node, container).Set(available)
-	m.containerRootfsCapacityBytes.WithLabelValues(pod, namespace, node, container).Set(capacity)
+	s.add(ContainerRootfsUsageBytes, used, pod, namespace, node, container)
+	s.add(ContainerRootfsAvailableBytes, available, pod, namespace, node, container)
+	s.add(ContainerRootfsCapacityBytes, capacity, pod, namespace, node, container)
 }
 
 // SetContainerRootfsInodes sets the root filesystem inode metrics for a specific container in a pod, namespace, and node.
-func (m *Metrics) SetContainerRootfsInodes(
+func (s *Snapshot) SetContainerRootfsInodes(
 	pod, namespace, node, container string,
 	used, available, capacity float64,
 ) {
-	m.containerRootfsInodesUsed.WithLabelValues(pod, namespace, node, container).Set(used)
-	m.containerRootfsInodesFree.WithLabelValues(pod, namespace, node, container).Set(available)
-	m.containerRootfsInodes.WithLabelValues(pod, namespace, node, container).Set(capacity)
+	s.add(ContainerRootfsInodesUsed, used, pod, namespace, node, container)
+	s.add(ContainerRootfsInodesFree, available, pod, namespace, node, container)
+	s.add(ContainerRootfsInodes, capacity, pod, namespace, node, container)
 }
 
 // SetContainerLogsValues sets the logs metrics for a specific container in a pod, namespace, and node.
-func (m *Metrics) SetContainerLogsValues(
+func (s *Snapshot) SetContainerLogsValues(
 	pod, namespace, node, container string,
 	used, available, capacity float64,
 ) {
-	m.containerLogsUsageBytes.WithLabelValues(pod, namespace, node, container).Set(used)
-	m.containerLogsAvailableBytes.WithLabelValues(pod, namespace, node, container).Set(available)
-	m.containerLogsCapacityBytes.WithLabelValues(pod, namespace, node, container).Set(capacity)
+	s.add(ContainerLogsUsageBytes, used, pod, namespace, node, container)
+	s.add(ContainerLogsAvailableBytes, available, pod, namespace, node, container)
+	s.add(ContainerLogsCapacityBytes, capacity, pod, namespace, node, container)
 }
 
 // SetContainerLogsInodes sets the logs inode metrics for a specific container in a pod, namespace, and node.
-func (m *Metrics) SetContainerLogsInodes(
+func (s *Snapshot) SetContainerLogsInodes(
 	pod, namespace, node, container string,
 	used, available, capacity float64,
 ) {
-	m.containerLogsInodesUsed.WithLabelValues(pod, namespace, node, container).Set(used)
-	m.containerLogsInodesFree.WithLabelValues(pod, namespace, node, container).Set(available)
-	m.containerLogsInodes.WithLabelValues(pod, namespace, node, container).Set(capacity)
+	s.add(ContainerLogsInodesUsed, used, pod, namespace, node, container)
+	s.add(ContainerLogsInodesFree, available, pod, namespace, node, container)
+	s.add(ContainerLogsInodes, capacity, pod, namespace, node, container)
 }
 
 // SetPodVolumeValues sets the pod volume metrics for a specific volume in a pod, namespace, and node.
-func (m *Metrics) SetPodVolumeValues(
+func (s *Snapshot) SetPodVolumeValues(
 	pod, namespace, node, volume string,
 	used, available, capacity float64,
 ) {
-	m.podVolumeUsageBytes.WithLabelValues(pod, namespace, node, volume).Set(used)
-	m.podVolumeAvailableBytes.WithLabelValues(pod, namespace, node, volume).Set(available)
-	m.podVolumeCapacityBytes.WithLabelValues(pod, namespace, node, volume).Set(capacity)
+	s.add(PodVolumeUsageBytes, used, pod, namespace, node, volume)
+	s.add(PodVolumeAvailableBytes, available, pod, namespace, node, volume)
+	s.add(PodVolumeCapacityBytes, capacity, pod, namespace, node, volume)
 }
 
 // SetPodVolumeInodes sets the pod volume inode metrics for a specific volume in a pod, namespace, and node.
-func (m *Metrics) SetPodVolumeInodes(
+func (s *Snapshot) SetPodVolumeInodes(
 	pod, namespace, node, volume string,
 	used, available, capacity float64,
 ) {
-	m.podVolumeInodesUsed.WithLabelValues(pod, namespace, node, volume).Set(used)
-	m.podVolumeInodesFree.WithLabelValues(pod, namespace, node, volume).Set(available)
-	m.podVolumeInodes.WithLabelValues(pod, namespace, node, volume).Set(capacity)
+	s.add(PodVolumeInodesUsed, used, pod, namespace, node, volume)
+	s.add(PodVolumeInodesFree, available, pod, namespace, node, volume)
+	s.add(PodVolumeInodes, capacity, pod, namespace, node, volume)
+}
+
+// SetPodVolumeInodeUsage sets the volume bytes per inode and inode pressure for a specific volume in a pod, namespace, and node.
+func (s *Snapshot) SetPodVolumeInodeUsage(
+	pod, namespace, node, volume string,
+	bytesPerInode, ratio float64,
+) {
+	s.add(PodVolumeBytesPerInode, bytesPerInode, pod, namespace, node, volume)
+	s.add(PodVolumeInodesRatio, ratio, pod, namespace, node, volume)
 }
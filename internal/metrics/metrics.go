@@ -1,6 +1,13 @@
 package metrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // constant values for namespace and subsystem
 const (
@@ -12,225 +19,142 @@ const (
 	SSPodVolume        = "pod_volume"
 )
 
-// Metrics holds the Prometheus metrics for the exporter.
+// Metrics holds the published snapshots of every node and exposes them as Prometheus metrics.
+// The collector publishes a complete snapshot per cycle, and scrapes only read published
+// snapshots, so a scrape never sees a partially updated cycle.
 type Metrics struct {
-	// API Metrics
-	apiStatus  *prometheus.GaugeVec
-	apiLatency *prometheus.GaugeVec
-
-	// Ephemeral Storage
-	ephemeralStorageAvailableBytes *prometheus.GaugeVec
-	ephemeralStorageCapacityBytes  *prometheus.GaugeVec
-	ephemeralStorageUsageBytes     *prometheus.GaugeVec
-	ephemeralStorageInodes         *prometheus.GaugeVec
-	ephemeralStorageInodesFree     *prometheus.GaugeVec
-	ephemeralStorageInodesUsed     *prometheus.GaugeVec
-
-	// Container Memory
-	containerMemoryAvailableBytes *prometheus.GaugeVec
-	containerMemoryCapacityBytes  *prometheus.GaugeVec
-	containerMemoryUsageBytes     *prometheus.GaugeVec
-
-	// Container RootFS
-	containerRootfsAvailableBytes *prometheus.GaugeVec
-	containerRootfsCapacityBytes  *prometheus.GaugeVec
-	containerRootfsUsageBytes     *prometheus.GaugeVec
-	containerRootfsInodes         *prometheus.GaugeVec
-	containerRootfsInodesFree     *prometheus.GaugeVec
-	containerRootfsInodesUsed     *prometheus.GaugeVec
-
-	// Container Logs
-	containerLogsAvailableBytes *prometheus.GaugeVec
-	containerLogsCapacityBytes  *prometheus.GaugeVec
-	containerLogsUsageBytes     *prometheus.GaugeVec
-	containerLogsInodes         *prometheus.GaugeVec
-	containerLogsInodesFree     *prometheus.GaugeVec
-	containerLogsInodesUsed     *prometheus.GaugeVec
-
-	// Pod Volume
-	podVolumeAvailableBytes *prometheus.GaugeVec
-	podVolumeCapacityBytes  *prometheus.GaugeVec
-	podVolumeUsageBytes     *prometheus.GaugeVec
-	podVolumeInodes         *prometheus.GaugeVec
-	podVolumeInodesFree     *prometheus.GaugeVec
-	podVolumeInodesUsed     *prometheus.GaugeVec
+	lock      sync.Mutex
+	snapshots atomic.Pointer[map[string]*Snapshot]
+
+	// Sink Metrics
+	sinkErrors  *prometheus.CounterVec
+	sinkLatency *prometheus.GaugeVec
 }
 
 // NewMetrics initializes and registers the Prometheus metrics for the exporter.
 func NewMetrics() (*Metrics, error) {
-	// create Prometheus metrics using the newGaugeVec helper function in utils.go
-	return &Metrics{
-		apiStatus: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Name:      "api_status",
-			Help:      "Summary API status on the target node (0 is down, 1 is up)",
-		}, []string{"exported_node"}),
-		apiLatency: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Name:      "api_latency",
-			Help:      "Summary API response time in seconds",
-		}, []string{"exported_node"}),
-		ephemeralStorageAvailableBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSEphemeralStorage,
-			Name:      "available_bytes",
-			Help:      "Ephemeral storage available space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node"}),
-		ephemeralStorageCapacityBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSEphemeralStorage,
-			Name:      "capacity_bytes",
-			Help:      "Ephemeral storage space capacity in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node"}),
-		ephemeralStorageUsageBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSEphemeralStorage,
-			Name:      "used_bytes",
-			Help:      "Ephemeral storage used space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node"}),
-		ephemeralStorageInodes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSEphemeralStorage,
-			Name:      "inodes_total",
-			Help:      "Ephemeral storage number of total inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node"}),
-		ephemeralStorageInodesFree: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSEphemeralStorage,
-			Name:      "inodes_free",
-			Help:      "Ephemeral storage number of free inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node"}),
-		ephemeralStorageInodesUsed: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSEphemeralStorage,
-			Name:      "inodes_used",
-			Help:      "Ephemeral storage number of used inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node"}),
-		containerMemoryAvailableBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerMemory,
-			Name:      "available_bytes",
-			Help:      "Container memory available space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerMemoryCapacityBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerMemory,
-			Name:      "capacity_bytes",
-			Help:      "Container memory capacity in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerMemoryUsageBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerMemory,
-			Name:      "usage_bytes",
-			Help:      "Container memory used space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerRootfsAvailableBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerRootFS,
-			Name:      "available_bytes",
-			Help:      "Container root file system available space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerRootfsCapacityBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerRootFS,
-			Name:      "capacity_bytes",
-			Help:      "Container root file system capacity in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerRootfsUsageBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerRootFS,
-			Name:      "usage_bytes",
-			Help:      "Container root file system used space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerRootfsInodes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerRootFS,
-			Name:      "inodes_total",
-			Help:      "Container root file system total number of inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerRootfsInodesFree: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerRootFS,
-			Name:      "inodes_free",
-			Help:      "Container root file system number of free inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerRootfsInodesUsed: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerRootFS,
-			Name:      "inodes_used",
-			Help:      "Container root file system number of used inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerLogsAvailableBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerLogs,
-			Name:      "available_bytes",
-			Help:      "Container logs space available in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerLogsCapacityBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerLogs,
-			Name:      "capacity_bytes",
-			Help:      "Container logs capacity in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerLogsUsageBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerLogs,
-			Name:      "usage_bytes",
-			Help:      "Container logs used space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerLogsInodes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerLogs,
-			Name:      "inodes_total",
-			Help:      "Container logs total number of inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerLogsInodesFree: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerLogs,
-			Name:      "inodes_free",
-			Help:      "Container logs number of free inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		containerLogsInodesUsed: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSContainerLogs,
-			Name:      "inodes_used",
-			Help:      "Container logs number of used inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_container"}),
-		podVolumeAvailableBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSPodVolume,
-			Name:      "available_bytes",
-			Help:      "Pod volume space available in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}),
-		podVolumeCapacityBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSPodVolume,
-			Name:      "capacity_bytes",
-			Help:      "Pod volume capacity in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}),
-		podVolumeUsageBytes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSPodVolume,
-			Name:      "usage_bytes",
-			Help:      "Pod volume used space in bytes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}),
-		podVolumeInodes: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSPodVolume,
-			Name:      "inodes_total",
-			Help:      "Pod volume total number of inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}),
-		podVolumeInodesFree: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSPodVolume,
-			Name:      "inodes_free",
-			Help:      "Pod volume number of free inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}),
-		podVolumeInodesUsed: newGaugeVec(prometheus.GaugeOpts{
-			Namespace: NS,
-			Subsystem: SSPodVolume,
-			Name:      "inodes_used",
-			Help:      "Pod volume number of used inodes",
-		}, []string{"exported_pod", "exported_namespace", "exported_node", "exported_volume"}),
-	}, nil
+	m := &Metrics{
+		sinkErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: NS,
+			Name:      "sink_errors_total",
+			Help:      "Number of failed snapshot writes per output sink",
+		}, []string{"sink"}),
+		sinkLatency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: NS,
+			Name:      "sink_write_seconds",
+			Help:      "Duration of the last snapshot write per output sink in seconds",
+		}, []string{"sink"}),
+	}
+	m.snapshots.Store(&map[string]*Snapshot{})
+
+	// register the metrics with Prometheus
+	if err := prometheus.Register(m); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return nil, err
+		}
+
+		existing, ok := are.ExistingCollector.(*Metrics)
+		if !ok {
+			return nil, errors.New("different metric type registration")
+		}
+
+		return existing, nil
+	}
+
+	return m, nil
+}
+
+// Name returns the name of the Prometheus sink.
+func (m *Metrics) Name() string {
+	return "prometheus"
+}
+
+// Write implements the sink interface by publishing the snapshot.
+func (m *Metrics) Write(_ context.Context, snapshot *Snapshot) error {
+	m.Publish(snapshot)
+
+	return nil
+}
+
+// ObserveSinkWrite records the duration and the result of a sink write.
+func (m *Metrics) ObserveSinkWrite(sink string, seconds float64, err error) {
+	m.sinkLatency.WithLabelValues(sink).Set(seconds)
+	if err != nil {
+		m.sinkErrors.WithLabelValues(sink).Inc()
+	}
+}
+
+// Publish atomically replaces the snapshot of the node.
+func (m *Metrics) Publish(snapshot *Snapshot) {
+	m.update(func(snapshots map[string]*Snapshot) {
+		snapshots[snapshot.Node] = snapshot
+	})
+}
+
+// Remove drops the snapshot of a node, e.g. when the node left the cluster.
+func (m *Metrics) Remove(node string) {
+	m.update(func(snapshots map[string]*Snapshot) {
+		delete(snapshots, node)
+	})
+}
+
+// Snapshots returns the published snapshots of all nodes.
+func (m *Metrics) Snapshots() []*Snapshot {
+	current := *m.snapshots.Load()
+
+	list := make([]*Snapshot, 0, len(current))
+	for _, snapshot := range current {
+		list = append(list, snapshot)
+	}
+
+	return list
+}
+
+// update copies the snapshots map, applies the change and swaps the maps.
+func (m *Metrics) update(change func(map[string]*Snapshot)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	current := *m.snapshots.Load()
+
+	next := make(map[string]*Snapshot, len(current)+1)
+	for node, snapshot := range current {
+		next[node] = snapshot
+	}
+
+	change(next)
+	m.snapshots.Store(&next)
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, def := range Definitions {
+		ch <- def.desc
+	}
+
+	m.sinkErrors.Describe(ch)
+	m.sinkLatency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, snapshot := range m.Snapshots() {
+		collectSnapshot(ch, snapshot)
+	}
+
+	m.sinkErrors.Collect(ch)
+	m.sinkLatency.Collect(ch)
+}
+
+// collectSnapshot sends the samples of the snapshot as gauges.
+func collectSnapshot(ch chan<- prometheus.Metric, snapshot *Snapshot) {
+	for _, sample := range snapshot.Samples {
+		ch <- prometheus.MustNewConstMetric(
+			sample.Definition.desc,
+			prometheus.GaugeValue,
+			sample.Value,
+			sample.LabelValues...,
+		)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"go.uber.org/zap"
@@ -13,7 +14,14 @@ import (
 func StartMetricsServer(logr *zap.Logger, port int) {
 	go func() {
 		// create a new HTTP server
-		http.Handle("/metrics", promhttp.Handler())
+		// a failed metric is logged and left out, instead of failing the whole scrape
+		http.Handle("/metrics", promhttp.InstrumentMetricHandler(
+			prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+				ErrorLog:      zap.NewStdLog(logr),
+				ErrorHandling: promhttp.ContinueOnError,
+			}),
+		))
 		addr := fmt.Sprintf(":%d", port)
 
 		logr.Info("starting metrics server", zap.String("address", addr))
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+)
+
+// Sample is a single value of a metric series.
+type Sample struct {
+	Definition  *Definition
+	LabelValues []string
+	Value       float64
+}
+
+// Snapshot holds every sample of a single collection cycle of a node. A snapshot is
+// built by the collector and must not be modified after it is published.
+type Snapshot struct {
+	Node      string
+	Timestamp time.Time
+	Samples   []Sample
+	// Duplicates is the number of samples that replaced a sample of the same series
+	Duplicates int
+
+	index map[string]int
+}
+
+// NewSnapshot creates an empty snapshot for the given node.
+func NewSnapshot(node string) *Snapshot {
+	return &Snapshot{
+		Node:      node,
+		Timestamp: time.Now(),
+	}
+}
+
+// add appends a sample to the snapshot. A sample of a series that is already in the
+// snapshot replaces its value, since a scrape fails on duplicated series.
+func (s *Snapshot) add(def *Definition, value float64, labels ...string) {
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+
+	key := def.FQName() + "\xff" + strings.Join(labels, "\xff")
+	if i, ok := s.index[key]; ok {
+		s.Samples[i].Value = value
+		s.Duplicates++
+
+		return
+	}
+
+	s.index[key] = len(s.Samples)
+	s.Samples = append(s.Samples, Sample{
+		Definition:  def,
+		LabelValues: labels,
+		Value:       value,
+	})
+}
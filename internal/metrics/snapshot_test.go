@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotDuplicates(t *testing.T) {
+	snapshot := NewSnapshot("node")
+
+	// two pods with the same name and namespace, like a pod during its recreation
+	snapshot.SetEphemeralStorageValues("web", "default", "node", 10, 90, 100)
+	snapshot.SetEphemeralStorageValues("web", "default", "node", 20, 80, 100)
+	snapshot.SetEphemeralStorageValues("api", "default", "node", 5, 95, 100)
+
+	if len(snapshot.Samples) != 6 {
+		t.Fatalf("expected 6 samples, got %d", len(snapshot.Samples))
+	}
+
+	if snapshot.Duplicates != 3 {
+		t.Errorf("expected 3 duplicates, got %d", snapshot.Duplicates)
+	}
+
+	if value := snapshot.Samples[0].Value; value != 20 {
+		t.Errorf("expected the last used bytes 20, got %v", value)
+	}
+
+	// the gather fails on duplicated series
+	var buf bytes.Buffer
+	if err := WriteText(&buf, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `ephemeral_storage_used_bytes{exported_namespace="default",exported_node="node",exported_pod="web"} 20`) {
+		t.Errorf("expected the last value of pod web in:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// snapshotCollector exposes the samples of a single snapshot, without registering
+// them with the default registry.
+type snapshotCollector struct {
+	snapshot *Snapshot
+}
+
+// Describe implements prometheus.Collector.
+func (c snapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, def := range Definitions {
+		ch <- def.desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c snapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	collectSnapshot(ch, c.snapshot)
+}
+
+// WriteText writes the samples of the snapshot in the Prometheus text exposition format.
+func WriteText(w io.Writer, snapshot *Snapshot) error {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(snapshotCollector{snapshot: snapshot}); err != nil {
+		return err
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
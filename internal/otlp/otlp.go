@@ -0,0 +1,176 @@
+package otlp
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.uber.org/zap"
+)
+
+// constant values for the supported OTLP protocols
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// attributeKeys maps the Prometheus label names to the Kubernetes semantic-convention attributes.
+var attributeKeys = map[string]attribute.Key{
+	"exported_pod":       semconv.K8SPodNameKey,
+	"exported_namespace": semconv.K8SNamespaceNameKey,
+	"exported_node":      semconv.K8SNodeNameKey,
+	"exported_container": semconv.K8SContainerNameKey,
+	"exported_volume":    semconv.K8SVolumeNameKey,
+}
+
+// Config holds the options of the OTLP exporter.
+type Config struct {
+	Endpoint string
+	Protocol string
+	Insecure bool
+	Interval time.Duration
+	NodeName string
+	PodName  string
+}
+
+// Exporter pushes the published metric snapshots to an OpenTelemetry collector.
+type Exporter struct {
+	provider *sdkmetric.MeterProvider
+}
+
+// NewExporter creates an OTLP exporter that periodically pushes every metric
+// defined in the metrics package as an OTel gauge.
+func NewExporter(logr *zap.Logger, cfg Config, mtx *metrics.Metrics) (*Exporter, error) {
+	exporter, err := newMetricExporter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP %s exporter: %w", cfg.Protocol, err)
+	}
+
+	// identify the exporter instance with the service attributes, the Kubernetes attributes
+	// are set on the data points, as the pods of the samples are not the exporter pod
+	attrs := []attribute.KeyValue{semconv.ServiceName("localsight")}
+	if instance := cmp.Or(cfg.PodName, cfg.NodeName); instance != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(instance))
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(cfg.Interval))),
+	)
+
+	// create a gauge for every metric definition
+	meter := provider.Meter("github.com/amirhnajafiz/localsight")
+	gauges := make(map[*metrics.Definition]metric.Float64ObservableGauge, len(metrics.Definitions))
+	instruments := make([]metric.Observable, 0, len(metrics.Definitions))
+
+	for _, def := range metrics.Definitions {
+		gauge, err := meter.Float64ObservableGauge(
+			def.FQName(),
+			metric.WithDescription(def.Help),
+			metric.WithUnit(unit(def)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gauge %s: %w", def.FQName(), err)
+		}
+
+		gauges[def] = gauge
+		instruments = append(instruments, gauge)
+	}
+
+	// observe the published snapshots on every collection of the reader
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, snapshot := range mtx.Snapshots() {
+			for _, sample := range snapshot.Samples {
+				o.ObserveFloat64(gauges[sample.Definition], sample.Value, metric.WithAttributes(attributes(sample)...))
+			}
+		}
+
+		return nil
+	}, instruments...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register OTLP callback: %w", err)
+	}
+
+	logr.Info(
+		"starting OTLP exporter",
+		zap.String("endpoint", cfg.Endpoint),
+		zap.String("protocol", cfg.Protocol),
+		zap.Duration("interval", cfg.Interval),
+	)
+
+	return &Exporter{provider: provider}, nil
+}
+
+// Shutdown flushes the pending metrics and stops the exporter.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.provider.Shutdown(ctx)
+}
+
+// newMetricExporter creates the OTLP exporter for the configured protocol.
+func newMetricExporter(cfg Config) (sdkmetric.Exporter, error) {
+	ctx := context.Background()
+
+	switch cfg.Protocol {
+	case ProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+
+		return otlpmetricgrpc.New(ctx, opts...)
+	case ProtocolHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown protocol: %s", cfg.Protocol)
+	}
+}
+
+// attributes converts the labels of a sample into OTel attributes.
+func attributes(sample metrics.Sample) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(sample.LabelValues))
+	for i, label := range sample.Definition.Labels {
+		key, ok := attributeKeys[label]
+		if !ok {
+			key = attribute.Key(label)
+		}
+
+		attrs = append(attrs, key.String(sample.LabelValues[i]))
+	}
+
+	return attrs
+}
+
+// unit returns the UCUM unit of a metric definition based on its name.
+func unit(def *metrics.Definition) string {
+	// the scores and the ratios of the inodes are checked before the inode counts
+	switch {
+	case strings.HasSuffix(def.Name, "_score"), strings.HasSuffix(def.Name, "_ratio"):
+		return "1"
+	case def.Name == "bytes_per_inode":
+		return "By/{inode}"
+	case strings.HasSuffix(def.Name, "_bytes"):
+		return "By"
+	case strings.HasPrefix(def.Name, "inodes"):
+		return "{inode}"
+	case def == metrics.APILatency:
+		return "s"
+	default:
+		return "1"
+	}
+}
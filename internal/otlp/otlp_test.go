@@ -0,0 +1,39 @@
+package otlp
+
+import (
+	"testing"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+func TestUnit(t *testing.T) {
+	tests := []struct {
+		def  *metrics.Definition
+		want string
+	}{
+		{def: metrics.APIStatus, want: "1"},
+		{def: metrics.APILatency, want: "s"},
+		{def: metrics.EphemeralStorageUsageBytes, want: "By"},
+		{def: metrics.EphemeralStorageCapacityBytes, want: "By"},
+		{def: metrics.ContainerMemoryUsageBytes, want: "By"},
+		{def: metrics.ContainerRootfsAvailableBytes, want: "By"},
+		{def: metrics.ContainerLogsUsageBytes, want: "By"},
+		{def: metrics.PodVolumeUsageBytes, want: "By"},
+		{def: metrics.EphemeralStorageInodes, want: "{inode}"},
+		{def: metrics.EphemeralStorageInodesUsed, want: "{inode}"},
+		{def: metrics.ContainerRootfsInodesFree, want: "{inode}"},
+		{def: metrics.PodVolumeInodesUsed, want: "{inode}"},
+		{def: metrics.EphemeralStorageBytesPerInode, want: "By/{inode}"},
+		{def: metrics.PodVolumeBytesPerInode, want: "By/{inode}"},
+		{def: metrics.EphemeralStorageInodesRatio, want: "1"},
+		{def: metrics.PodVolumeInodesRatio, want: "1"},
+		{def: metrics.EphemeralStorageUsedAnomaly, want: "1"},
+		{def: metrics.EphemeralStorageInodesAnomaly, want: "1"},
+	}
+
+	for _, tt := range tests {
+		if got := unit(tt.def); got != tt.want {
+			t.Errorf("%s: expected unit %q, got %q", tt.def.FQName(), tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/kube"
+
+	"go.uber.org/zap"
+)
+
+// constant values for the member leases
+const (
+	memberLabel = "localsight.io/member"
+	leasePrefix = "localsight-member-"
+	// staleLeases is the number of lease durations after which the lease of a member
+	// that did not leave cleanly, e.g. of a killed pod, is deleted
+	staleLeases = 10
+)
+
+// Registry keeps the membership list of the replicas using one Lease object per
+// replica, and divides the nodes between the live members with a consistent hashing ring.
+type Registry struct {
+	Client        *kube.Client
+	Namespace     string
+	Identity      string
+	LeaseDuration time.Duration
+	Logr          *zap.Logger
+
+	lock    sync.Mutex
+	members []string
+	ring    *Ring
+	done    chan struct{}
+}
+
+// Start renews the lease of this replica in the background, until the context is done.
+// The lease is renewed three times per lease duration, so a single failed renewal does
+// not drop the member.
+func (r *Registry) Start(ctx context.Context) {
+	r.ring = NewRing([]string{r.Identity})
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.LeaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			if err := r.heartbeat(); err != nil {
+				r.Logr.Error("failed to renew member lease", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Leave waits for the renewals to stop and deletes the lease of this replica, so the
+// other replicas take over its nodes at once instead of after the lease expires.
+func (r *Registry) Leave() error {
+	<-r.done
+
+	err := r.Client.DeleteLease(r.Namespace, leasePrefix+r.Identity)
+	if err != nil && !kube.IsNotFound(err) {
+		return fmt.Errorf("failed to delete member lease: %w", err)
+	}
+
+	return nil
+}
+
+// Filter returns the nodes owned by this replica, according to the current membership list.
+func (r *Registry) Filter(nodes []string) []string {
+	if err := r.refresh(); err != nil {
+		r.Logr.Error("failed to refresh members, using the last known list", zap.Error(err))
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return owned(r.ring, r.Identity, nodes)
+}
+
+// owned returns the nodes that the ring assigns to the member.
+func owned(ring *Ring, member string, nodes []string) []string {
+	list := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if ring.Owner(node) == member {
+			list = append(list, node)
+		}
+	}
+
+	return list
+}
+
+// heartbeat creates or renews the lease of this replica.
+func (r *Registry) heartbeat() error {
+	name := leasePrefix + r.Identity
+
+	lease, err := r.Client.GetLease(r.Namespace, name)
+	if kube.IsNotFound(err) {
+		lease = kube.NewLease(r.Namespace, name, map[string]string{memberLabel: "true"})
+		lease.Renew(r.Identity, r.LeaseDuration, time.Now())
+
+		return r.Client.CreateLease(lease)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get lease: %w", err)
+	}
+
+	lease.Renew(r.Identity, r.LeaseDuration, time.Now())
+
+	return r.Client.UpdateLease(lease)
+}
+
+// refresh lists the live member leases and rebuilds the ring when the members change.
+// The stale leases of the members that are long gone are deleted.
+func (r *Registry) refresh() error {
+	leases, err := r.Client.ListLeases(r.Namespace, memberLabel+"=true")
+	if err != nil {
+		return err
+	}
+
+	members, stale := liveMembers(leases, r.Identity, time.Now(), staleLeases*r.LeaseDuration)
+
+	for _, lease := range stale {
+		err := r.Client.DeleteLease(r.Namespace, lease.Metadata.Name)
+		if err != nil && !kube.IsNotFound(err) {
+			r.Logr.Warn("failed to delete stale member lease", zap.String("lease", lease.Metadata.Name), zap.Error(err))
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !slices.Equal(members, r.members) {
+		r.Logr.Info("sharding members changed", zap.Strings("members", members))
+
+		r.members = members
+		r.ring = NewRing(members)
+	}
+
+	return nil
+}
+
+// liveMembers returns the sorted holders of the leases that have not expired, with this
+// replica, and the leases that expired more than the stale period ago.
+func liveMembers(leases []kube.Lease, identity string, now time.Time, stale time.Duration) ([]string, []kube.Lease) {
+	members := []string{identity}
+
+	var expired []kube.Lease
+	for _, lease := range leases {
+		if lease.Spec.HolderIdentity == identity {
+			continue
+		}
+
+		if lease.Expired(now) {
+			if lease.Expired(now.Add(-stale)) {
+				expired = append(expired, lease)
+			}
+
+			continue
+		}
+
+		members = append(members, lease.Spec.HolderIdentity)
+	}
+
+	slices.Sort(members)
+
+	return members, expired
+}
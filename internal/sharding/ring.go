@@ -0,0 +1,71 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"slices"
+	"strconv"
+)
+
+// virtualNodes is the number of points each member takes on the ring, which
+// spreads the keys evenly between a small number of members.
+const virtualNodes = 128
+
+// Ring is a consistent hashing ring that maps keys to members. Adding or removing
+// a member only moves the keys that belonged to the neighbouring points.
+type Ring struct {
+	points  []uint64
+	members map[uint64]string
+}
+
+// NewRing creates a ring with the given members.
+func NewRing(members []string) *Ring {
+	r := &Ring{
+		points:  make([]uint64, 0, len(members)*virtualNodes),
+		members: make(map[uint64]string, len(members)*virtualNodes),
+	}
+
+	for _, member := range members {
+		for i := range virtualNodes {
+			point := hash(member + "#" + strconv.Itoa(i))
+
+			r.points = append(r.points, point)
+			r.members[point] = member
+		}
+	}
+
+	slices.Sort(r.points)
+
+	return r
+}
+
+// Owner returns the member that owns the key, or an empty string if the ring has no members.
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	// find the first point clockwise from the key hash
+	i, _ := slices.BinarySearch(r.points, hash(key))
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.members[r.points[i]]
+}
+
+// hash returns the 64-bit FNV-1a hash of the value, mixed with the MurmurHash3 finalizer.
+// FNV-1a alone barely spreads values that differ in the last bytes, like the virtual
+// points of a member, which leaves the members with very uneven shares of the ring.
+func hash(value string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
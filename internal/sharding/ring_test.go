@@ -0,0 +1,117 @@
+package sharding
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/kube"
+)
+
+// assign returns the nodes of every member of the ring.
+func assign(members, nodes []string) map[string][]string {
+	ring := NewRing(members)
+
+	assigned := make(map[string][]string, len(members))
+	for _, member := range members {
+		assigned[member] = owned(ring, member, nodes)
+	}
+
+	return assigned
+}
+
+// owners returns the member of every node.
+func owners(assigned map[string][]string) map[string]string {
+	list := make(map[string]string)
+	for member, nodes := range assigned {
+		for _, node := range nodes {
+			list[node] = member
+		}
+	}
+
+	return list
+}
+
+func TestRingMembership(t *testing.T) {
+	nodes := make([]string, 300)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf("node-%03d", i)
+	}
+
+	steps := []struct {
+		name    string
+		members []string
+		joined  string
+	}{
+		{name: "single member", members: []string{"a"}},
+		{name: "member joined", members: []string{"a", "b"}, joined: "b"},
+		{name: "second member joined", members: []string{"a", "b", "c"}, joined: "c"},
+		{name: "member left", members: []string{"a", "c"}},
+	}
+
+	var previous map[string]string
+	for _, step := range steps {
+		assigned := assign(step.members, nodes)
+
+		// every node is collected by exactly one member
+		total := 0
+		for member, list := range assigned {
+			total += len(list)
+
+			if len(step.members) > 1 && len(list) < len(nodes)/len(step.members)/2 {
+				t.Errorf("%s: member %s owns only %d nodes", step.name, member, len(list))
+			}
+		}
+
+		current := owners(assigned)
+		if total != len(nodes) || len(current) != len(nodes) {
+			t.Fatalf("%s: expected %d assigned nodes, got %d", step.name, len(nodes), total)
+		}
+
+		// the nodes only move to the joined member or from the left member
+		for node, owner := range current {
+			before, ok := previous[node]
+			if !ok || before == owner {
+				continue
+			}
+
+			if slices.Contains(step.members, before) && owner != step.joined {
+				t.Errorf("%s: node %s moved from %s to %s", step.name, node, before, owner)
+			}
+		}
+
+		previous = current
+	}
+}
+
+func TestLiveMembers(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lease := func(holder string, renewed time.Duration) kube.Lease {
+		l := kube.NewLease("default", leasePrefix+holder, nil)
+		l.Renew(holder, 15*time.Second, now.Add(-renewed))
+
+		return *l
+	}
+
+	leases := []kube.Lease{
+		lease("c", 0),
+		lease("self", 0),
+		lease("b", 10*time.Second),
+		// expired, but may come back
+		lease("d", time.Minute),
+		// expired long ago
+		lease("e", time.Hour),
+	}
+
+	members, stale := liveMembers(leases, "self", now, 10*15*time.Second)
+
+	if want := []string{"b", "c", "self"}; !slices.Equal(members, want) {
+		t.Errorf("expected members %v, got %v", want, members)
+	}
+
+	if len(stale) != 1 || stale[0].Spec.HolderIdentity != "e" {
+		t.Errorf("expected the stale lease of e, got %v", stale)
+	}
+}
@@ -0,0 +1,283 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/kube"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// constant values of the emitted events
+const (
+	component = "localsight"
+
+	ReasonEphemeralStorage = "EphemeralStorageNearLimit"
+	ReasonVolumeSpace      = "VolumeLowAvailableSpace"
+	ReasonAnomaly          = "EphemeralStorageGrowthAnomaly"
+)
+
+// Config holds the thresholds and the limits of the events sink.
+type Config struct {
+	// EphemeralRatio is the fraction of the pod ephemeral-storage limit that triggers an event.
+	EphemeralRatio float64
+	// VolumeMinAvailable is the available bytes of a volume below which an event is emitted.
+	VolumeMinAvailable int64
+	// AnomalyThreshold is the anomaly score that triggers an event, 0 disables it.
+	AnomalyThreshold float64
+	// Dedup is the period in which the same event is not emitted again.
+	Dedup time.Duration
+	// Rate is the maximum number of events per minute.
+	Rate int
+	// PodCacheTTL is how long the listed pods of a node are reused.
+	PodCacheTTL time.Duration
+}
+
+// podCache holds the listed pods of a node. The fields are set once the ready channel
+// is closed, so a single list of a node is in flight at a time.
+type podCache struct {
+	listed time.Time
+	pods   map[string]*kube.Pod
+	ready  chan struct{}
+}
+
+// done returns true if the pods of the cache are listed.
+func (c *podCache) done() bool {
+	select {
+	case <-c.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sink emits Kubernetes Warning events on pods that cross the storage thresholds.
+type Sink struct {
+	client  *kube.Client
+	cfg     Config
+	logr    *zap.Logger
+	limiter *limiter
+
+	lock    sync.Mutex
+	pods    map[string]*podCache
+	emitted map[string]time.Time
+}
+
+// New creates an events sink.
+func New(logr *zap.Logger, client *kube.Client, cfg Config) *Sink {
+	return &Sink{
+		client:  client,
+		cfg:     cfg,
+		logr:    logr,
+		limiter: newLimiter(cfg.Rate),
+		pods:    make(map[string]*podCache),
+		emitted: make(map[string]time.Time),
+	}
+}
+
+// Name returns the name of the sink.
+func (s *Sink) Name() string {
+	return "events"
+}
+
+// SetConfig replaces the thresholds and the limits of the sink.
+func (s *Sink) SetConfig(cfg Config) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if cfg.Rate != s.cfg.Rate {
+		s.limiter = newLimiter(cfg.Rate)
+	}
+
+	s.cfg = cfg
+}
+
+// config returns the current configuration of the sink.
+func (s *Sink) config() Config {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.cfg
+}
+
+// Write checks the samples of the snapshot against the thresholds and emits the events.
+func (s *Sink) Write(_ context.Context, snapshot *metrics.Snapshot) error {
+	var errs []error
+
+	cfg := s.config()
+	for _, sample := range snapshot.Samples {
+		var err error
+
+		switch sample.Definition {
+		case metrics.EphemeralStorageUsageBytes:
+			err = s.checkEphemeralStorage(cfg, sample)
+		case metrics.PodVolumeAvailableBytes:
+			err = s.checkVolume(cfg, sample)
+		case metrics.EphemeralStorageUsedAnomaly, metrics.EphemeralStorageInodesAnomaly:
+			err = s.checkAnomaly(cfg, sample)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to emit %d events, first error: %w", len(errs), errs[0])
+	}
+
+	return nil
+}
+
+// checkEphemeralStorage compares the pod ephemeral storage usage with its limit.
+func (s *Sink) checkEphemeralStorage(cfg Config, sample metrics.Sample) error {
+	if cfg.EphemeralRatio <= 0 {
+		return nil
+	}
+
+	pod, namespace, node := sample.LabelValues[0], sample.LabelValues[1], sample.LabelValues[2]
+
+	obj, err := s.pod(node, namespace, pod)
+	if err != nil || obj == nil {
+		return err
+	}
+
+	limit := obj.Limit("ephemeral-storage")
+	if limit == 0 || sample.Value < cfg.EphemeralRatio*float64(limit) {
+		return nil
+	}
+
+	message := fmt.Sprintf(
+		"Pod is using %.0f bytes of ephemeral storage, %.1f%% of its %d bytes limit",
+		sample.Value, 100*sample.Value/float64(limit), limit,
+	)
+
+	return s.emit(obj, node, ReasonEphemeralStorage, "", message)
+}
+
+// checkVolume compares the available bytes of a pod volume with the threshold.
+func (s *Sink) checkVolume(cfg Config, sample metrics.Sample) error {
+	if cfg.VolumeMinAvailable <= 0 || sample.Value >= float64(cfg.VolumeMinAvailable) {
+		return nil
+	}
+
+	pod, namespace, node, volume := sample.LabelValues[0], sample.LabelValues[1], sample.LabelValues[2], sample.LabelValues[3]
+
+	obj, err := s.pod(node, namespace, pod)
+	if err != nil || obj == nil {
+		return err
+	}
+
+	message := fmt.Sprintf(
+		"Volume %s has %.0f bytes available, below the %d bytes threshold",
+		volume, sample.Value, cfg.VolumeMinAvailable,
+	)
+
+	return s.emit(obj, node, ReasonVolumeSpace, volume, message)
+}
+
+// checkAnomaly compares the anomaly score of the pod ephemeral storage growth with the threshold.
+func (s *Sink) checkAnomaly(cfg Config, sample metrics.Sample) error {
+	if cfg.AnomalyThreshold <= 0 || sample.Value < cfg.AnomalyThreshold {
+		return nil
+	}
+
+	pod, namespace, node := sample.LabelValues[0], sample.LabelValues[1], sample.LabelValues[2]
+
+	obj, err := s.pod(node, namespace, pod)
+	if err != nil || obj == nil {
+		return err
+	}
+
+	what := "used bytes"
+	if sample.Definition == metrics.EphemeralStorageInodesAnomaly {
+		what = "used inodes"
+	}
+
+	message := fmt.Sprintf(
+		"Ephemeral storage %s grew %.1f deviations above the expected rate",
+		what, sample.Value,
+	)
+
+	// the bytes and the inodes anomalies of a pod are deduplicated separately
+	return s.emit(obj, node, ReasonAnomaly, sample.Definition.Name, message)
+}
+
+// emit creates the event unless it was emitted recently or the rate limit is reached. The
+// subject, e.g. the volume, tells apart the events of a pod with the same reason.
+func (s *Sink) emit(pod *kube.Pod, node, reason, subject, message string) error {
+	key := pod.Metadata.Namespace + "/" + pod.Metadata.Name + "/" + reason + "/" + subject
+	now := time.Now()
+
+	s.lock.Lock()
+	last, ok := s.emitted[key]
+	if ok && now.Sub(last) < s.cfg.Dedup {
+		s.lock.Unlock()
+		return nil
+	}
+
+	if !s.limiter.allow() {
+		s.lock.Unlock()
+		s.logr.Warn("events rate limit reached, dropped event", zap.String("key", key))
+		return nil
+	}
+
+	s.emitted[key] = now
+	s.cleanup(now)
+	s.lock.Unlock()
+
+	ref := kube.ObjectReference{
+		Name:      pod.Metadata.Name,
+		Namespace: pod.Metadata.Namespace,
+		UID:       pod.Metadata.UID,
+	}
+
+	return s.client.CreateEvent(kube.NewPodWarning(ref, reason, message, component, node, now))
+}
+
+// cleanup forgets the emitted events that are older than the dedup period.
+func (s *Sink) cleanup(now time.Time) {
+	for key, last := range s.emitted {
+		if now.Sub(last) >= s.cfg.Dedup {
+			delete(s.emitted, key)
+		}
+	}
+}
+
+// pod returns the pod object from the cache of its node, listing the node pods when
+// the cache is expired. It returns nil if the pod no longer exists. The lock is not held
+// during the list, and the samples of a node that is being listed wait for its list.
+func (s *Sink) pod(node, namespace, name string) (*kube.Pod, error) {
+	s.lock.Lock()
+	cache, ok := s.pods[node]
+	if ok && (!cache.done() || time.Since(cache.listed) <= s.cfg.PodCacheTTL) {
+		s.lock.Unlock()
+		<-cache.ready
+
+		return cache.pods[namespace+"/"+name], nil
+	}
+
+	cache = &podCache{ready: make(chan struct{})}
+	s.pods[node] = cache
+	s.lock.Unlock()
+
+	pods, err := s.client.ListNodePods(node)
+
+	// a failed list is cached as well, so it is not retried for every sample
+	cache.pods = make(map[string]*kube.Pod, len(pods))
+	for i := range pods {
+		cache.pods[pods[i].Metadata.Namespace+"/"+pods[i].Metadata.Name] = &pods[i]
+	}
+	cache.listed = time.Now()
+	close(cache.ready)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of node %s: %w", node, err)
+	}
+
+	return cache.pods[namespace+"/"+name], nil
+}
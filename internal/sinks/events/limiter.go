@@ -0,0 +1,42 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket that allows a number of events per minute, with
+// bursts up to the same number.
+type limiter struct {
+	lock   sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter creates a limiter that allows the given number of events per minute.
+func newLimiter(perMinute int) *limiter {
+	return &limiter{
+		rate:   float64(perMinute),
+		tokens: float64(perMinute),
+		last:   time.Now(),
+	}
+}
+
+// allow takes a token from the bucket, and returns false if the bucket is empty.
+func (l *limiter) allow() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Minutes()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+
+	return true
+}
@@ -0,0 +1,128 @@
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+	"github.com/amirhnajafiz/localsight/internal/sinks"
+)
+
+// escapers of the line protocol elements
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// Sink writes the snapshots as InfluxDB line protocol over HTTP or UDP.
+// Every metric is a measurement with its labels as tags and a single value field.
+type Sink struct {
+	token string
+	url   string
+	http  *http.Client
+	conn  net.Conn
+}
+
+// New creates an Influx sink. The address is either an HTTP write URL, e.g.
+// http://influxdb:8086/api/v2/write?org=org&bucket=bucket, or a udp://host:port address.
+func New(address, token string, timeout time.Duration) (*Sink, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse influx address: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return &Sink{
+			token: token,
+			url:   address,
+			http:  &http.Client{Timeout: timeout},
+		}, nil
+	case "udp":
+		conn, err := net.Dial("udp", u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial influx UDP address: %w", err)
+		}
+
+		return &Sink{conn: conn}, nil
+	default:
+		return nil, fmt.Errorf("unsupported influx scheme: %s", u.Scheme)
+	}
+}
+
+// Name returns the name of the sink.
+func (s *Sink) Name() string {
+	return "influx"
+}
+
+// Write encodes the snapshot and sends it to InfluxDB.
+func (s *Sink) Write(ctx context.Context, snapshot *metrics.Snapshot) error {
+	lines := Lines(snapshot)
+
+	if s.conn != nil {
+		return sinks.WriteDatagrams(ctx, s.conn, lines)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(bytes.Join(lines, []byte{'\n'})))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// Lines converts the samples of a snapshot into line protocol lines with nanosecond timestamps.
+func Lines(snapshot *metrics.Snapshot) [][]byte {
+	ts := strconv.FormatInt(snapshot.Timestamp.UnixNano(), 10)
+
+	lines := make([][]byte, 0, len(snapshot.Samples))
+	for _, sample := range snapshot.Samples {
+		var b strings.Builder
+
+		b.WriteString(measurementEscaper.Replace(sample.Definition.FQName()))
+		for i, name := range sample.Definition.Labels {
+			// the line protocol does not allow empty tag values
+			if sample.LabelValues[i] == "" {
+				continue
+			}
+
+			b.WriteByte(',')
+			b.WriteString(tagEscaper.Replace(name))
+			b.WriteByte('=')
+			b.WriteString(tagEscaper.Replace(sample.LabelValues[i]))
+		}
+
+		b.WriteString(" value=")
+		b.WriteString(strconv.FormatFloat(sample.Value, 'g', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(ts)
+
+		lines = append(lines, []byte(b.String()))
+	}
+
+	return lines
+}
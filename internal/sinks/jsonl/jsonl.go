@@ -0,0 +1,74 @@
+package jsonl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// Stdout is the output path that writes the records to the standard output. The logs
+// are written to the standard error, so the output holds only the records.
+const Stdout = "stdout"
+
+// Sink writes every snapshot as JSON lines to a file or to the standard output.
+// Files are rotated when they exceed the maximum size.
+type Sink struct {
+	lock   sync.Mutex
+	output *rotator
+}
+
+// New creates a JSON lines sink for the given path.
+func New(path string, maxSize int64, maxFiles int, compress bool) (*Sink, error) {
+	if path == Stdout {
+		return &Sink{output: &rotator{file: os.Stdout}}, nil
+	}
+
+	output, err := newRotator(path, maxSize, maxFiles, compress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sink{output: output}, nil
+}
+
+// Name returns the name of the sink.
+func (s *Sink) Name() string {
+	return "jsonl"
+}
+
+// Write encodes the records of the snapshot and appends them to the output.
+func (s *Sink) Write(_ context.Context, snapshot *metrics.Snapshot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	buf := bufio.NewWriter(s.output)
+	encoder := json.NewEncoder(buf)
+
+	for _, record := range Records(snapshot) {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	return s.output.rotate()
+}
+
+// Close closes the output file. The standard output is left open.
+func (s *Sink) Close(_ context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.output.path == "" {
+		return nil
+	}
+
+	return s.output.file.Close()
+}
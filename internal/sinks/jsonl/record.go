@@ -0,0 +1,78 @@
+package jsonl
+
+import (
+	"strings"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// Record is a single JSON line, holding all values of a pod, container or volume
+// of a subsystem in one collection cycle.
+type Record struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Node      string             `json:"node"`
+	Kind      string             `json:"kind"`
+	Namespace string             `json:"namespace,omitempty"`
+	Pod       string             `json:"pod,omitempty"`
+	Container string             `json:"container,omitempty"`
+	Volume    string             `json:"volume,omitempty"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// Records groups the samples of a snapshot into records, keeping the order of the samples.
+func Records(snapshot *metrics.Snapshot) []*Record {
+	var records []*Record
+	index := make(map[string]*Record)
+
+	for _, sample := range snapshot.Samples {
+		def := sample.Definition
+		key := def.Subsystem + "\x00" + strings.Join(sample.LabelValues, "\x00")
+
+		record, ok := index[key]
+		if !ok {
+			record = newRecord(snapshot, sample)
+			index[key] = record
+			records = append(records, record)
+		}
+
+		record.Values[def.Name] = sample.Value
+	}
+
+	return records
+}
+
+// newRecord creates an empty record from the labels of a sample.
+func newRecord(snapshot *metrics.Snapshot, sample metrics.Sample) *Record {
+	record := &Record{
+		Timestamp: snapshot.Timestamp,
+		Node:      snapshot.Node,
+		Kind:      sample.Definition.Subsystem,
+		Values:    make(map[string]float64),
+	}
+
+	if record.Kind == "" {
+		record.Kind = "api"
+	}
+
+	for i, name := range sample.Definition.Labels {
+		value := sample.LabelValues[i]
+
+		switch name {
+		case "exported_node":
+			if value != "" {
+				record.Node = value
+			}
+		case "exported_namespace":
+			record.Namespace = value
+		case "exported_pod":
+			record.Pod = value
+		case "exported_container":
+			record.Container = value
+		case "exported_volume":
+			record.Volume = value
+		}
+	}
+
+	return record
+}
@@ -0,0 +1,178 @@
+package jsonl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// rotationLayout is the timestamp suffix of the rotated files.
+const rotationLayout = "20060102T150405.000"
+
+// rotator is a file writer that moves the file aside once it exceeds the maximum size,
+// optionally compresses it, and keeps a limited number of rotated files.
+type rotator struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	compress bool
+
+	file *os.File
+	size int64
+}
+
+// newRotator opens the file for appending.
+func newRotator(path string, maxSize int64, maxFiles int, compress bool) (*rotator, error) {
+	r := &rotator{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		compress: compress,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write appends the data to the current file.
+func (r *rotator) Write(p []byte) (int, error) {
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// open opens the output file and reads its current size.
+func (r *rotator) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+
+	return nil
+}
+
+// rotate moves the current file aside when it exceeds the maximum size.
+func (r *rotator) rotate() error {
+	if r.path == "" || r.maxSize <= 0 || r.size < r.maxSize {
+		return nil
+	}
+
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	rotated := fmt.Sprintf("%s.%s", r.path, time.Now().UTC().Format(rotationLayout))
+	if err := os.Rename(r.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate output file: %w", err)
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	if r.compress {
+		if err := compressFile(rotated); err != nil {
+			return fmt.Errorf("failed to compress rotated file: %w", err)
+		}
+	}
+
+	return r.cleanup()
+}
+
+// cleanup removes the oldest rotated files beyond the maximum number of files.
+func (r *rotator) cleanup() error {
+	if r.maxFiles <= 0 {
+		return nil
+	}
+
+	matches, err := r.rotated()
+	if err != nil {
+		return err
+	}
+
+	// the timestamp suffix sorts the files from the oldest to the newest
+	slices.SortFunc(matches, strings.Compare)
+	for len(matches) > r.maxFiles {
+		if err := os.Remove(matches[0]); err != nil {
+			return err
+		}
+
+		matches = matches[1:]
+	}
+
+	return nil
+}
+
+// rotated returns the rotated files of the output file, compressed or not. The other
+// files that share the name of the output file are left alone.
+func (r *rotator) rotated() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(r.path) + "."
+
+	var files []string
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		if _, err := time.Parse(rotationLayout, strings.TrimSuffix(suffix, ".gz")); err == nil {
+			files = append(files, filepath.Join(filepath.Dir(r.path), entry.Name()))
+		}
+	}
+
+	return files, nil
+}
+
+// compressFile writes a gzip copy of the file and removes the original.
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
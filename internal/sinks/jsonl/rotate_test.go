@@ -0,0 +1,145 @@
+package jsonl
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// writeSnapshots writes the snapshots to the sink, a few milliseconds apart so that
+// every rotated file gets its own timestamp.
+func writeSnapshots(t *testing.T, sink *Sink, count int) {
+	t.Helper()
+
+	for range count {
+		snapshot := metrics.NewSnapshot("node")
+		snapshot.SetEphemeralStorageValues("web", "default", "node", 10, 90, 100)
+
+		if err := sink.Write(context.Background(), snapshot); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestRotateSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+
+	sink, err := New(path, 1, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close(context.Background())
+
+	writeSnapshots(t, sink, 3)
+
+	rotated, err := sink.output.rotated()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rotated) != 3 {
+		t.Fatalf("expected 3 rotated files, got %v", rotated)
+	}
+
+	// every rotated file holds a whole snapshot, and the output starts empty
+	for _, file := range rotated {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if lines := strings.Count(string(data), "\n"); lines == 0 {
+			t.Errorf("expected records in %s", file)
+		}
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("expected an empty output file, got %v, %v", info, err)
+	}
+}
+
+func TestRotateCompress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+
+	sink, err := New(path, 1, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close(context.Background())
+
+	writeSnapshots(t, sink, 1)
+
+	rotated, err := sink.output.rotated()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rotated) != 1 || !strings.HasSuffix(rotated[0], ".gz") {
+		t.Fatalf("expected a compressed rotated file, got %v", rotated)
+	}
+
+	file, err := os.Open(rotated[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"pod":"web"`) {
+		t.Errorf("expected the records of pod web, got %s", data)
+	}
+}
+
+func TestRotateRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.jsonl")
+
+	// the files that share the name of the output but are not its rotations
+	unrelated := []string{"out.jsonl.bak", "out.jsonl.old.gz", "out.jsonl.2020"}
+	for _, name := range unrelated {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink, err := New(path, 1, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close(context.Background())
+
+	writeSnapshots(t, sink, 4)
+
+	rotated, err := sink.output.rotated()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", rotated)
+	}
+
+	for _, name := range unrelated {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be kept: %v", name, err)
+		}
+	}
+}
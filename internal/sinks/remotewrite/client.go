@@ -0,0 +1,243 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"github.com/golang/snappy"
+	"go.uber.org/zap"
+)
+
+// constant values of the retry backoff
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+// maxMergedBatches is the number of queued batches merged into a single request.
+const maxMergedBatches = 10
+
+// Config holds the options of the remote write client.
+type Config struct {
+	URL         string
+	Username    string
+	Password    string
+	BearerToken string
+	QueueSize   int
+	MaxRetries  int
+	Timeout     time.Duration
+}
+
+// Client pushes the snapshots to a Prometheus remote write endpoint. Snapshots are
+// queued in a bounded in-memory queue, when the queue is full the oldest batch is dropped.
+type Client struct {
+	cfg   Config
+	logr  *zap.Logger
+	http  *http.Client
+	queue chan []TimeSeries
+	done  chan struct{}
+}
+
+// recoverableError is returned for failures that are worth retrying.
+type recoverableError struct {
+	error
+}
+
+// NewClient creates a remote write client and starts its sender.
+func NewClient(logr *zap.Logger, cfg Config) *Client {
+	c := &Client{
+		cfg:   cfg,
+		logr:  logr,
+		http:  &http.Client{Timeout: cfg.Timeout},
+		queue: make(chan []TimeSeries, max(cfg.QueueSize, 1)),
+		done:  make(chan struct{}),
+	}
+
+	logr.Info(
+		"starting remote write client",
+		zap.String("url", cfg.URL),
+		zap.Int("queue", cap(c.queue)),
+	)
+
+	go c.run()
+
+	return c
+}
+
+// Name returns the name of the sink.
+func (c *Client) Name() string {
+	return "remote-write"
+}
+
+// Write implements sinks.Sink by queueing the snapshot, the batches are sent in the background.
+func (c *Client) Write(_ context.Context, snapshot *metrics.Snapshot) error {
+	c.Enqueue(snapshot)
+
+	return nil
+}
+
+// Enqueue adds the samples of a snapshot to the queue without blocking the collector.
+func (c *Client) Enqueue(snapshot *metrics.Snapshot) {
+	batch := FromSnapshot(snapshot)
+
+	for {
+		select {
+		case c.queue <- batch:
+			return
+		default:
+		}
+
+		// the queue is full, drop the oldest batch to make room
+		select {
+		case <-c.queue:
+			c.logr.Warn("remote write queue is full, dropped the oldest batch")
+		default:
+		}
+	}
+}
+
+// Close stops accepting snapshots and waits for the queued batches to be sent, until
+// the context is done. It must be called after the last write.
+func (c *Client) Close(ctx context.Context) error {
+	close(c.queue)
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to drain the queue, %d batches left: %w", len(c.queue), ctx.Err())
+	}
+}
+
+// run sends the queued batches, retrying the recoverable failures. The batches that queued
+// up while a request was in flight are merged, so a slow endpoint does not fall behind.
+func (c *Client) run() {
+	defer close(c.done)
+
+	for first := range c.queue {
+		batch := c.merge(first)
+		backoff := minBackoff
+
+		for attempt := 0; ; attempt++ {
+			err := c.send(batch)
+			if err == nil {
+				break
+			}
+
+			var re recoverableError
+			if !errors.As(err, &re) || attempt >= c.cfg.MaxRetries {
+				c.logr.Error("failed to send remote write batch, dropped", zap.Int("series", len(batch)), zap.Error(err))
+				break
+			}
+
+			c.logr.Warn("failed to send remote write batch, retrying", zap.Duration("backoff", backoff), zap.Error(err))
+
+			time.Sleep(backoff)
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+}
+
+// merge appends the samples of up to maxMergedBatches queued batches to the series of
+// the given batch. The batches are queued in order, so the samples stay sorted by time.
+func (c *Client) merge(batch []TimeSeries) []TimeSeries {
+	index := make(map[string]int, len(batch))
+	merged := make([]TimeSeries, 0, len(batch))
+
+	add := func(series []TimeSeries) {
+		for _, s := range series {
+			key := seriesKey(s.Labels)
+			if i, ok := index[key]; ok {
+				merged[i].Samples = append(merged[i].Samples, s.Samples...)
+				continue
+			}
+
+			// the samples are cloned to not grow the slice of the queued batch
+			s.Samples = slices.Clone(s.Samples)
+			index[key] = len(merged)
+			merged = append(merged, s)
+		}
+	}
+
+	add(batch)
+
+	for range maxMergedBatches - 1 {
+		select {
+		case next, ok := <-c.queue:
+			if !ok {
+				return merged
+			}
+
+			add(next)
+		default:
+			return merged
+		}
+	}
+
+	return merged
+}
+
+// seriesKey returns a unique key of a sorted label set.
+func seriesKey(labels []Label) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(label.Name)
+		b.WriteByte(0xff)
+		b.WriteString(label.Value)
+		b.WriteByte(0xff)
+	}
+
+	return b.String()
+}
+
+// send compresses and posts a batch to the remote write endpoint.
+func (c *Client) send(batch []TimeSeries) error {
+	body := snappy.Encode(nil, Encode(batch))
+
+	req, err := http.NewRequest(http.MethodPost, c.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "localsight")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	// set the authentication header
+	switch {
+	case c.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.BearerToken)
+	case c.cfg.Username != "":
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return recoverableError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	err = fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+
+	// server errors and throttling are retried, other client errors are not
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+
+	return err
+}
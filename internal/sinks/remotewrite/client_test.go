@@ -0,0 +1,135 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+)
+
+func TestClientRoundTrip(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = make(map[string][]prompb.Sample)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" {
+			t.Errorf("expected the snappy encoding, got %q", r.Header.Get("Content-Encoding"))
+		}
+
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		body, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			t.Errorf("failed to decompress the request: %v", err)
+			return
+		}
+
+		var req prompb.WriteRequest
+		if err := req.Unmarshal(body); err != nil {
+			t.Errorf("failed to unmarshal the request: %v", err)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, ts := range req.Timeseries {
+			labels := make([]string, 0, len(ts.Labels))
+			for _, label := range ts.Labels {
+				labels = append(labels, label.Name+"="+label.Value)
+			}
+
+			key := strings.Join(labels, ",")
+			received[key] = append(received[key], ts.Samples...)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(zap.NewNop(), Config{URL: server.URL, QueueSize: 10, Timeout: time.Second})
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		snapshot := metrics.NewSnapshot("node")
+		snapshot.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		snapshot.SetEphemeralStorageValues("web", "default", "node", float64(10*(i+1)), 90, 100)
+
+		if err := c.Write(context.Background(), snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	key := "__name__=ls_ex_ephemeral_storage_used_bytes,exported_namespace=default,exported_node=node,exported_pod=web"
+
+	samples := received[key]
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples of %s, got %v in %v", key, samples, received)
+	}
+
+	for i, sample := range samples {
+		value, ts := float64(10*(i+1)), start.Add(time.Duration(i)*time.Minute).UnixMilli()
+		if sample.Value != value || sample.Timestamp != ts {
+			t.Errorf("sample %d: expected %v at %d, got %v at %d", i, value, ts, sample.Value, sample.Timestamp)
+		}
+	}
+}
+
+func TestClientMerge(t *testing.T) {
+	c := &Client{queue: make(chan []TimeSeries, maxMergedBatches+5)}
+
+	series := func(name string, ts int64) TimeSeries {
+		return TimeSeries{
+			Labels:  []Label{{Name: "__name__", Value: name}},
+			Samples: []Sample{{Value: float64(ts), Timestamp: ts}},
+		}
+	}
+
+	first := []TimeSeries{series("a", 0)}
+	for i := range maxMergedBatches + 4 {
+		c.queue <- []TimeSeries{series("a", int64(i+1)), series("b", int64(i+1))}
+	}
+
+	merged := c.merge(first)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 series, got %d", len(merged))
+	}
+
+	// the first batch and the next queued batches up to the limit
+	if n := len(merged[0].Samples); n != maxMergedBatches {
+		t.Errorf("expected %d samples of a, got %d", maxMergedBatches, n)
+	}
+
+	for i, sample := range merged[0].Samples {
+		if sample.Timestamp != int64(i) {
+			t.Errorf("expected the samples in order, got %v", merged[0].Samples)
+			break
+		}
+	}
+
+	if len(first[0].Samples) != 1 {
+		t.Errorf("expected the queued batch to be unchanged, got %v", first[0].Samples)
+	}
+
+	if left := len(c.queue); left != 5 {
+		t.Errorf("expected 5 batches left in the queue, got %d", left)
+	}
+}
@@ -0,0 +1,228 @@
+package remotewrite
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Label is a name and value pair of a time series.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value of a time series at a timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// TimeSeries is a labeled series of samples, as defined by the remote write protocol.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// FromSnapshot converts the samples of a snapshot into remote write time series.
+func FromSnapshot(snapshot *metrics.Snapshot) []TimeSeries {
+	ts := snapshot.Timestamp.UnixMilli()
+
+	series := make([]TimeSeries, 0, len(snapshot.Samples))
+	for _, sample := range snapshot.Samples {
+		labels := make([]Label, 0, len(sample.LabelValues)+1)
+		labels = append(labels, Label{Name: "__name__", Value: sample.Definition.FQName()})
+		for i, name := range sample.Definition.Labels {
+			labels = append(labels, Label{Name: name, Value: sample.LabelValues[i]})
+		}
+
+		// the protocol requires the labels to be sorted by name
+		slices.SortFunc(labels, func(a, b Label) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		series = append(series, TimeSeries{
+			Labels:  labels,
+			Samples: []Sample{{Value: sample.Value, Timestamp: ts}},
+		})
+	}
+
+	return series
+}
+
+// Encode marshals the time series into a prometheus.WriteRequest protobuf message.
+func Encode(series []TimeSeries) []byte {
+	var buf []byte
+	for _, s := range series {
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, encodeTimeSeries(s))
+	}
+
+	return buf
+}
+
+// encodeTimeSeries marshals a prometheus.TimeSeries message.
+func encodeTimeSeries(s TimeSeries) []byte {
+	var buf []byte
+	for _, label := range s.Labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, label.Name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, label.Value)
+
+		buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, lb)
+	}
+
+	for _, sample := range s.Samples {
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(sample.Value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(sample.Timestamp))
+
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, sb)
+	}
+
+	return buf
+}
+
+// Decode unmarshals a prometheus.WriteRequest protobuf message. It is the counterpart of
+// Encode and is used by stand-in receivers.
+func Decode(data []byte) ([]TimeSeries, error) {
+	var series []TimeSeries
+
+	err := forEachField(data, func(num protowire.Number, value []byte) error {
+		if num != 1 {
+			return nil
+		}
+
+		s, err := decodeTimeSeries(value)
+		if err != nil {
+			return err
+		}
+
+		series = append(series, s)
+
+		return nil
+	})
+
+	return series, err
+}
+
+// decodeTimeSeries unmarshals a prometheus.TimeSeries message.
+func decodeTimeSeries(data []byte) (TimeSeries, error) {
+	var s TimeSeries
+
+	err := forEachField(data, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 1:
+			var label Label
+			err := forEachField(value, func(num protowire.Number, value []byte) error {
+				switch num {
+				case 1:
+					label.Name = string(value)
+				case 2:
+					label.Value = string(value)
+				}
+
+				return nil
+			})
+
+			s.Labels = append(s.Labels, label)
+
+			return err
+		case 2:
+			sample, err := decodeSample(value)
+			s.Samples = append(s.Samples, sample)
+
+			return err
+		}
+
+		return nil
+	})
+
+	return s, err
+}
+
+// decodeSample unmarshals a prometheus.Sample message.
+func decodeSample(data []byte) (Sample, error) {
+	var sample Sample
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return sample, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			if n < 0 {
+				return sample, protowire.ParseError(n)
+			}
+
+			sample.Value = math.Float64frombits(v)
+			data = data[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return sample, protowire.ParseError(n)
+			}
+
+			sample.Timestamp = int64(v)
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return sample, protowire.ParseError(n)
+			}
+
+			data = data[n:]
+		}
+	}
+
+	return sample, nil
+}
+
+// forEachField calls the handler with every length-delimited field of a message
+// and skips the other field types.
+func forEachField(data []byte, handler func(protowire.Number, []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		if err := handler(num, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// maxDatagramSize keeps the UDP packets below the common Ethernet MTU.
+const maxDatagramSize = 1432
+
+// Sink receives the snapshot of every collection cycle. Write must return once
+// the context is done.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, snapshot *metrics.Snapshot) error
+}
+
+// Closer is a sink that holds resources, e.g. queued batches or open files, which are
+// released when the exporter stops.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// FanOut writes every snapshot to all sinks concurrently, with a timeout per sink.
+// Failed writes are logged and counted in the sink error metrics. A sink whose
+// previous write of the same node is still running after its timeout skips the snapshot.
+type FanOut struct {
+	logr    *zap.Logger
+	metrics *metrics.Metrics
+	timeout time.Duration
+	sinks   []Sink
+
+	lock sync.Mutex
+	busy map[busyKey]struct{}
+}
+
+// busyKey identifies the running write of a node to a sink.
+type busyKey struct {
+	sink int
+	node string
+}
+
+// NewFanOut creates a fan-out over the given sinks.
+func NewFanOut(logr *zap.Logger, mtx *metrics.Metrics, timeout time.Duration, sinks ...Sink) *FanOut {
+	return &FanOut{
+		logr:    logr,
+		metrics: mtx,
+		timeout: timeout,
+		sinks:   sinks,
+		busy:    make(map[busyKey]struct{}),
+	}
+}
+
+// Write writes the snapshot to all sinks and waits for them to finish or time out.
+func (f *FanOut) Write(snapshot *metrics.Snapshot) {
+	var wg sync.WaitGroup
+	for i, sink := range f.sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := f.write(busyKey{sink: i, node: snapshot.Node}, sink, snapshot)
+
+			f.metrics.ObserveSinkWrite(sink.Name(), time.Since(start).Seconds(), err)
+			if err != nil {
+				f.logr.Error(
+					"failed to write snapshot to sink",
+					zap.String("sink", sink.Name()),
+					zap.String("node", snapshot.Node),
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Close closes the sinks that hold resources, one by one in the order of the sinks, and
+// returns the joined errors.
+func (f *FanOut) Close(ctx context.Context) error {
+	var errs []error
+	for _, sink := range f.sinks {
+		closer, ok := sink.(Closer)
+		if !ok {
+			continue
+		}
+
+		if err := closer.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// write calls the sink with a timeout, and stops waiting for sinks that ignore the context.
+// The node is marked busy until the sink returns, so a hanging sink does not pile up writes.
+func (f *FanOut) write(key busyKey, sink Sink, snapshot *metrics.Snapshot) error {
+	if !f.acquire(key) {
+		return errors.New("previous sink write is still running, skipped")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer f.release(key)
+
+		done <- sink.Write(ctx, snapshot)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("sink write timed out")
+	}
+}
+
+// acquire marks the write of a node to a sink as running, and reports false if it already is.
+func (f *FanOut) acquire(key busyKey) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if _, ok := f.busy[key]; ok {
+		return false
+	}
+
+	f.busy[key] = struct{}{}
+
+	return true
+}
+
+// release marks the write of a node to a sink as finished.
+func (f *FanOut) release(key busyKey) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.busy, key)
+}
+
+// WriteDatagrams writes the lines to a UDP connection, packing as many newline
+// separated lines into each datagram as fit in the maximum datagram size.
+func WriteDatagrams(ctx context.Context, conn net.Conn, lines [][]byte) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	packet := make([]byte, 0, maxDatagramSize)
+
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+len(line)+1 > maxDatagramSize {
+			if _, err := conn.Write(packet); err != nil {
+				return err
+			}
+
+			packet = packet[:0]
+		}
+
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+
+	if len(packet) > 0 {
+		if _, err := conn.Write(packet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
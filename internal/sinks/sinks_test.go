@@ -0,0 +1,103 @@
+package sinks
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// hangingSink ignores the context and blocks until it is released.
+type hangingSink struct {
+	release chan struct{}
+	writes  atomic.Int32
+
+	lock  sync.Mutex
+	nodes []string
+}
+
+func (h *hangingSink) Name() string {
+	return "hanging"
+}
+
+func (h *hangingSink) Write(_ context.Context, snapshot *metrics.Snapshot) error {
+	h.lock.Lock()
+	h.nodes = append(h.nodes, snapshot.Node)
+	h.lock.Unlock()
+
+	h.writes.Add(1)
+	<-h.release
+
+	return nil
+}
+
+func TestFanOutSkipsBusySink(t *testing.T) {
+	sink := &hangingSink{release: make(chan struct{})}
+	f := NewFanOut(zap.NewNop(), nil, 10*time.Millisecond, sink)
+
+	snapshot := metrics.NewSnapshot("node")
+	key := busyKey{sink: 0, node: snapshot.Node}
+
+	if err := f.write(key, sink, snapshot); err == nil {
+		t.Fatal("expected the write to time out")
+	}
+
+	// the previous write of the node is still running, so the next one is skipped
+	if err := f.write(key, sink, snapshot); err == nil {
+		t.Fatal("expected the write to be skipped")
+	}
+
+	if n := sink.writes.Load(); n != 1 {
+		t.Fatalf("expected 1 write, got %d", n)
+	}
+
+	close(sink.release)
+	for !f.acquire(key) {
+		time.Sleep(time.Millisecond)
+	}
+	f.release(key)
+
+	if err := f.write(key, sink, snapshot); err != nil {
+		t.Fatalf("unexpected error after the sink returned: %v", err)
+	}
+}
+
+func TestFanOutConcurrentNodes(t *testing.T) {
+	mtx, err := metrics.NewMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &hangingSink{release: make(chan struct{})}
+	f := NewFanOut(zap.NewNop(), mtx, 5*time.Second, sink)
+
+	// the cluster workers write the snapshots of different nodes at the same time
+	var wg sync.WaitGroup
+	for _, node := range []string{"node-a", "node-b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			f.Write(metrics.NewSnapshot(node))
+		}()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sink.writes.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(sink.release)
+	wg.Wait()
+
+	slices.Sort(sink.nodes)
+	if want := []string{"node-a", "node-b"}; !slices.Equal(sink.nodes, want) {
+		t.Errorf("expected the snapshots of %v, got %v", want, sink.nodes)
+	}
+}
@@ -0,0 +1,71 @@
+package statsd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+	"github.com/amirhnajafiz/localsight/internal/sinks"
+)
+
+// tagEscaper removes the characters that have a meaning in the DogStatsD format.
+var tagEscaper = strings.NewReplacer(",", "_", "|", "_", "#", "_")
+
+// Sink writes the snapshots as DogStatsD gauges over UDP, with the metric labels as tags.
+type Sink struct {
+	conn net.Conn
+}
+
+// New creates a StatsD sink for the agent at the given host:port address.
+func New(address string) (*Sink, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial statsd address: %w", err)
+	}
+
+	return &Sink{conn: conn}, nil
+}
+
+// Name returns the name of the sink.
+func (s *Sink) Name() string {
+	return "statsd"
+}
+
+// Write encodes the snapshot as gauges and sends them to the agent.
+func (s *Sink) Write(ctx context.Context, snapshot *metrics.Snapshot) error {
+	return sinks.WriteDatagrams(ctx, s.conn, Lines(snapshot))
+}
+
+// Lines converts the samples of a snapshot into DogStatsD gauge lines.
+func Lines(snapshot *metrics.Snapshot) [][]byte {
+	lines := make([][]byte, 0, len(snapshot.Samples))
+	for _, sample := range snapshot.Samples {
+		var b strings.Builder
+
+		b.WriteString(sample.Definition.FQName())
+		b.WriteByte(':')
+		b.WriteString(strconv.FormatFloat(sample.Value, 'f', -1, 64))
+		b.WriteString("|g")
+
+		sep := "|#"
+		for i, name := range sample.Definition.Labels {
+			if sample.LabelValues[i] == "" {
+				continue
+			}
+
+			b.WriteString(sep)
+			b.WriteString(name)
+			b.WriteByte(':')
+			b.WriteString(tagEscaper.Replace(sample.LabelValues[i]))
+
+			sep = ","
+		}
+
+		lines = append(lines, []byte(b.String()))
+	}
+
+	return lines
+}
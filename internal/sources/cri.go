@@ -0,0 +1,209 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/pkg/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+const (
+	// criPodUIDLabel is the label set by the kubelet on containers to reference their pod.
+	criPodUIDLabel = "io.kubernetes.pod.uid"
+)
+
+// CRI reads pod and container stats from the container runtime socket and maps them
+// into the kubelet summary format. The runtime only reports the writable layer and
+// memory usage, therefore logs, volumes and capacity values are left empty.
+type CRI struct {
+	nodeName string
+	endpoint string
+	timeout  time.Duration
+
+	conn   *grpc.ClientConn
+	client runtimeapi.RuntimeServiceClient
+}
+
+// NewCRI creates a CRI source connected to the given runtime endpoint
+// (e.g. unix:///run/containerd/containerd.sock).
+func NewCRI(nodeName, endpoint string, timeout time.Duration) (*CRI, error) {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CRI client: %w", err)
+	}
+
+	return &CRI{
+		nodeName: nodeName,
+		endpoint: endpoint,
+		timeout:  timeout,
+		conn:     conn,
+		client:   runtimeapi.NewRuntimeServiceClient(conn),
+	}, nil
+}
+
+// Endpoint returns the address of the CRI runtime socket.
+func (c *CRI) Endpoint() string {
+	return c.endpoint
+}
+
+// Close closes the connection to the runtime.
+func (c *CRI) Close() error {
+	return c.conn.Close()
+}
+
+// Fetch lists the pod sandbox and container stats and builds a summary from them.
+func (c *CRI) Fetch() (*types.Summary, *Metadata, error) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	sandboxes, err := c.client.ListPodSandboxStats(ctx, &runtimeapi.ListPodSandboxStatsRequest{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pod sandbox stats: %w", err)
+	}
+
+	containers, err := c.client.ListContainerStats(ctx, &runtimeapi.ListContainerStatsRequest{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list container stats: %w", err)
+	}
+
+	// the stats filters have no state, so the ready sandboxes and running containers
+	// are listed to drop the stats of the recreated sandboxes and exited attempts
+	ready, err := c.client.ListPodSandbox(ctx, &runtimeapi.ListPodSandboxRequest{
+		Filter: &runtimeapi.PodSandboxFilter{
+			State: &runtimeapi.PodSandboxStateValue{State: runtimeapi.PodSandboxState_SANDBOX_READY},
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pod sandboxes: %w", err)
+	}
+
+	running, err := c.client.ListContainers(ctx, &runtimeapi.ListContainersRequest{
+		Filter: &runtimeapi.ContainerFilter{
+			State: &runtimeapi.ContainerStateValue{State: runtimeapi.ContainerState_CONTAINER_RUNNING},
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	readyIDs := make(map[string]bool, len(ready.GetItems()))
+	for _, sandbox := range ready.GetItems() {
+		readyIDs[sandbox.GetId()] = true
+	}
+
+	runningIDs := make(map[string]bool, len(running.GetContainers()))
+	for _, container := range running.GetContainers() {
+		runningIDs[container.GetId()] = true
+	}
+
+	summary := buildCRISummary(c.nodeName, sandboxes.GetStats(), containers.GetStats(), readyIDs, runningIDs)
+
+	return summary, newMetadata(SourceCRI, c.endpoint, start), nil
+}
+
+// buildCRISummary builds a summary from the stats of the ready sandboxes and of the
+// running containers. When a pod or a container still has more than one of them, only
+// its newest attempt is kept.
+func buildCRISummary(
+	nodeName string,
+	sandboxes []*runtimeapi.PodSandboxStats,
+	containers []*runtimeapi.ContainerStats,
+	ready, running map[string]bool,
+) *types.Summary {
+	// keep the newest ready sandbox of every pod
+	newest := make(map[string]*runtimeapi.PodSandboxStats, len(sandboxes))
+	order := make([]string, 0, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		if !ready[sandbox.GetAttributes().GetId()] {
+			continue
+		}
+
+		uid := sandbox.GetAttributes().GetMetadata().GetUid()
+		current, ok := newest[uid]
+		if !ok {
+			order = append(order, uid)
+		}
+		if !ok || sandbox.GetAttributes().GetMetadata().GetAttempt() > current.GetAttributes().GetMetadata().GetAttempt() {
+			newest[uid] = sandbox
+		}
+	}
+
+	summary := &types.Summary{
+		Node: types.NodeSummary{NodeName: nodeName},
+		Pods: make([]types.PodSummary, 0, len(order)),
+	}
+
+	// index the pods by their uid, so containers can be attached to them
+	index := make(map[string]int, len(order))
+	for _, uid := range order {
+		meta := newest[uid].GetAttributes().GetMetadata()
+
+		var pod types.PodSummary
+		pod.PodRef.Name = meta.GetName()
+		pod.PodRef.Namespace = meta.GetNamespace()
+		pod.PodRef.UID = meta.GetUid()
+
+		index[uid] = len(summary.Pods)
+		summary.Pods = append(summary.Pods, pod)
+	}
+
+	// keep the newest running attempt of every container of the pods
+	type containerKey struct{ uid, name string }
+	attempts := make(map[containerKey]*runtimeapi.ContainerStats, len(containers))
+	keys := make([]containerKey, 0, len(containers))
+	for _, stats := range containers {
+		if !running[stats.GetAttributes().GetId()] {
+			continue
+		}
+
+		key := containerKey{
+			uid:  stats.GetAttributes().GetLabels()[criPodUIDLabel],
+			name: stats.GetAttributes().GetMetadata().GetName(),
+		}
+		if _, ok := index[key.uid]; !ok {
+			continue
+		}
+
+		current, ok := attempts[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+		if !ok || stats.GetAttributes().GetMetadata().GetAttempt() > current.GetAttributes().GetMetadata().GetAttempt() {
+			attempts[key] = stats
+		}
+	}
+
+	for _, key := range keys {
+		container := mapCRIContainer(attempts[key])
+
+		// the pod ephemeral storage is the sum of its containers writable layers
+		pod := &summary.Pods[index[key.uid]]
+		pod.EphemeralStorage.UsedBytes += container.Rootfs.UsedBytes
+		pod.EphemeralStorage.InodesUsed += container.Rootfs.InodesUsed
+		pod.Containers = append(pod.Containers, container)
+	}
+
+	return summary
+}
+
+// mapCRIContainer converts the CRI container stats into a container summary.
+func mapCRIContainer(stats *runtimeapi.ContainerStats) types.ContainerSummary {
+	var container types.ContainerSummary
+
+	container.Name = stats.GetAttributes().GetMetadata().GetName()
+
+	container.Memory.UsageBytes = stats.GetMemory().GetUsageBytes().GetValue()
+	container.Memory.AvailableBytes = stats.GetMemory().GetAvailableBytes().GetValue()
+
+	container.Rootfs.UsedBytes = stats.GetWritableLayer().GetUsedBytes().GetValue()
+	container.Rootfs.InodesUsed = stats.GetWritableLayer().GetInodesUsed().GetValue()
+
+	return container
+}
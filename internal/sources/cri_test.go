@@ -0,0 +1,85 @@
+package sources
+
+import (
+	"testing"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// sandboxStats returns the stats of a pod sandbox attempt.
+func sandboxStats(id, uid, name string, attempt uint32) *runtimeapi.PodSandboxStats {
+	return &runtimeapi.PodSandboxStats{
+		Attributes: &runtimeapi.PodSandboxAttributes{
+			Id:       id,
+			Metadata: &runtimeapi.PodSandboxMetadata{Name: name, Namespace: "default", Uid: uid, Attempt: attempt},
+		},
+	}
+}
+
+// containerStats returns the stats of a container attempt with its writable layer usage.
+func containerStats(id, uid, name string, attempt uint32, used uint64) *runtimeapi.ContainerStats {
+	return &runtimeapi.ContainerStats{
+		Attributes: &runtimeapi.ContainerAttributes{
+			Id:       id,
+			Metadata: &runtimeapi.ContainerMetadata{Name: name, Attempt: attempt},
+			Labels:   map[string]string{criPodUIDLabel: uid},
+		},
+		WritableLayer: &runtimeapi.FilesystemUsage{UsedBytes: &runtimeapi.UInt64Value{Value: used}},
+	}
+}
+
+func TestBuildCRISummary(t *testing.T) {
+	sandboxes := []*runtimeapi.PodSandboxStats{
+		// a recreated sandbox, the first one is not ready anymore
+		sandboxStats("s1", "u1", "web", 0),
+		sandboxStats("s2", "u1", "web", 1),
+		// two ready sandboxes of the same pod during a recreation
+		sandboxStats("s3", "u2", "api", 0),
+		sandboxStats("s4", "u2", "api", 1),
+	}
+
+	containers := []*runtimeapi.ContainerStats{
+		// a restarted container, the exited attempt still has a writable layer
+		containerStats("c1", "u1", "app", 0, 1000),
+		containerStats("c2", "u1", "app", 1, 10),
+		containerStats("c3", "u1", "sidecar", 0, 5),
+		// two running attempts of the same container
+		containerStats("c4", "u2", "app", 2, 100),
+		containerStats("c5", "u2", "app", 3, 200),
+		// a container of a pod without a ready sandbox
+		containerStats("c6", "u3", "app", 0, 1),
+	}
+
+	ready := map[string]bool{"s2": true, "s3": true, "s4": true}
+	running := map[string]bool{"c2": true, "c3": true, "c4": true, "c5": true, "c6": true}
+
+	summary := buildCRISummary("node", sandboxes, containers, ready, running)
+
+	if len(summary.Pods) != 2 {
+		t.Fatalf("expected 2 pods, got %d", len(summary.Pods))
+	}
+
+	tests := []struct {
+		name       string
+		containers int
+		used       uint64
+	}{
+		{name: "web", containers: 2, used: 15},
+		{name: "api", containers: 1, used: 200},
+	}
+
+	for i, tt := range tests {
+		pod := summary.Pods[i]
+		if pod.PodRef.Name != tt.name {
+			t.Errorf("pod %d: expected %s, got %s", i, tt.name, pod.PodRef.Name)
+		}
+
+		if len(pod.Containers) != tt.containers {
+			t.Errorf("pod %s: expected %d containers, got %d", tt.name, tt.containers, len(pod.Containers))
+		}
+
+		if pod.EphemeralStorage.UsedBytes != tt.used {
+			t.Errorf("pod %s: expected %d used bytes, got %d", tt.name, tt.used, pod.EphemeralStorage.UsedBytes)
+		}
+	}
+}
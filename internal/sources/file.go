@@ -0,0 +1,72 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// File reads a recorded kubelet summary from a JSON file on every fetch.
+// It is useful for feeding fixtures to the collector.
+type File struct {
+	path string
+}
+
+// NewFile creates a file source for the given path.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Endpoint returns the path of the summary file.
+func (f *File) Endpoint() string {
+	return f.path
+}
+
+// Fetch reads and decodes the summary file.
+func (f *File) Fetch() (*types.Summary, *Metadata, error) {
+	start := time.Now()
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read summary file: %w", err)
+	}
+
+	var summary types.Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode summary file: %w", err)
+	}
+
+	return &summary, newMetadata(SourceFile, f.path, start), nil
+}
+
+// Stream reads the summary file and decodes it pod by pod.
+func (f *File) Stream(fn func(node *types.NodeSummary, pod *types.PodSummary)) (*Metadata, error) {
+	start := time.Now()
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary file: %w", err)
+	}
+	defer file.Close()
+
+	if err := types.StreamSummary(file, fn); err != nil {
+		return nil, fmt.Errorf("failed to decode summary file: %w", err)
+	}
+
+	return newMetadata(SourceFile, f.path, start), nil
+}
+
+// FetchRaw reads the summary file without decoding it.
+func (f *File) FetchRaw() ([]byte, *Metadata, error) {
+	start := time.Now()
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read summary file: %w", err)
+	}
+
+	return data, newMetadata(SourceFile, f.path, start), nil
+}
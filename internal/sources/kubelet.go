@@ -0,0 +1,90 @@
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/pkg/fetch"
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// Kubelet reads the summary from the kubelet summary endpoint using client certificates.
+type Kubelet struct {
+	certFile string
+	keyFile  string
+	req      *http.Request
+}
+
+// NewKubelet creates a kubelet source for the given endpoint. An empty endpoint
+// falls back to the local kubelet summary endpoint.
+func NewKubelet(endpoint, certFile, keyFile string) (*Kubelet, error) {
+	// build the HTTP request to the kubelet summary endpoint
+	req, err := buildHTTPRequest(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build HTTP request: %w", err)
+	}
+
+	return &Kubelet{
+		certFile: certFile,
+		keyFile:  keyFile,
+		req:      req,
+	}, nil
+}
+
+// Endpoint returns the URL of the kubelet summary endpoint.
+func (k *Kubelet) Endpoint() string {
+	return k.req.URL.String()
+}
+
+// Fetch fetches and decodes the kubelet summary.
+func (k *Kubelet) Fetch() (*types.Summary, *Metadata, error) {
+	start := time.Now()
+
+	// perform the HTTP GET request
+	resp, err := fetch.GET(k.req, k.certFile, k.keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch kubelet summary: %w", err)
+	}
+
+	// decode the JSON response into a summary structure
+	var summary types.Summary
+	if err := fetch.JSON(resp, &summary); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode kubelet summary JSON: %w", err)
+	}
+
+	return &summary, newMetadata(SourceKubelet, k.Endpoint(), start), nil
+}
+
+// Stream fetches the kubelet summary and decodes it pod by pod.
+func (k *Kubelet) Stream(fn func(node *types.NodeSummary, pod *types.PodSummary)) (*Metadata, error) {
+	start := time.Now()
+
+	resp, err := fetch.GET(k.req, k.certFile, k.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch kubelet summary: %w", err)
+	}
+
+	if err := fetch.Summary(resp, fn); err != nil {
+		return nil, fmt.Errorf("failed to decode kubelet summary JSON: %w", err)
+	}
+
+	return newMetadata(SourceKubelet, k.Endpoint(), start), nil
+}
+
+// FetchRaw fetches the kubelet summary without decoding it.
+func (k *Kubelet) FetchRaw() ([]byte, *Metadata, error) {
+	start := time.Now()
+
+	resp, err := fetch.GET(k.req, k.certFile, k.keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch kubelet summary: %w", err)
+	}
+
+	data, err := fetch.Body(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read kubelet summary: %w", err)
+	}
+
+	return data, newMetadata(SourceKubelet, k.Endpoint(), start), nil
+}
@@ -0,0 +1,76 @@
+package sources
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/kube"
+	"github.com/amirhnajafiz/localsight/pkg/fetch"
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// Proxy reads the kubelet summary of a node through the API server node proxy.
+// It is used when direct access to the kubelet port is forbidden.
+type Proxy struct {
+	client *kube.Client
+	path   string
+}
+
+// NewProxy creates a proxy source for the given node.
+func NewProxy(client *kube.Client, nodeName string) *Proxy {
+	return &Proxy{
+		client: client,
+		path:   fmt.Sprintf("/api/v1/nodes/%s/proxy/stats/summary", url.PathEscape(nodeName)),
+	}
+}
+
+// Endpoint returns the URL of the node proxy summary endpoint.
+func (p *Proxy) Endpoint() string {
+	return p.client.Host() + p.path
+}
+
+// Fetch fetches and decodes the kubelet summary through the API server.
+func (p *Proxy) Fetch() (*types.Summary, *Metadata, error) {
+	start := time.Now()
+
+	var summary types.Summary
+	if err := p.client.GetJSON(p.path, &summary); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch summary through API server: %w", err)
+	}
+
+	return &summary, newMetadata(SourceProxy, p.Endpoint(), start), nil
+}
+
+// Stream fetches the kubelet summary through the API server and decodes it pod by pod.
+func (p *Proxy) Stream(fn func(node *types.NodeSummary, pod *types.PodSummary)) (*Metadata, error) {
+	start := time.Now()
+
+	resp, err := p.client.Get(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch summary through API server: %w", err)
+	}
+
+	if err := fetch.Summary(resp, fn); err != nil {
+		return nil, fmt.Errorf("failed to decode summary JSON: %w", err)
+	}
+
+	return newMetadata(SourceProxy, p.Endpoint(), start), nil
+}
+
+// FetchRaw fetches the kubelet summary through the API server without decoding it.
+func (p *Proxy) FetchRaw() ([]byte, *Metadata, error) {
+	start := time.Now()
+
+	resp, err := p.client.Get(p.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch summary through API server: %w", err)
+	}
+
+	data, err := fetch.Body(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+
+	return data, newMetadata(SourceProxy, p.Endpoint(), start), nil
+}
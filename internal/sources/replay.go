@@ -0,0 +1,161 @@
+package sources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/archive"
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// ErrReplayFinished is returned by the replay source after the last record of the archive.
+var ErrReplayFinished = errors.New("replay finished, the archive has no more records")
+
+// Replay feeds the summaries of a recorded archive to the collector. The records are
+// replayed on the recorded timeline scaled by the speed, so a fetch returns the latest
+// record that is due. A speed of zero returns the next record on every fetch. The fetch
+// time of a record is its recorded time, shifted by the length of the archive on every
+// loop, so the timestamps keep increasing.
+type Replay struct {
+	path  string
+	speed float64
+	loop  bool
+
+	reader  *archive.Reader
+	current *archive.Record
+	next    *archive.Record
+	done    bool
+	// origin is the recorded time of the first record, and start is the time it was replayed
+	origin time.Time
+	start  time.Time
+	// offset shifts the recorded times of a looped replay, and records counts the
+	// records of the current loop
+	offset  time.Duration
+	records int
+}
+
+// NewReplay creates a replay source for the archive. When loop is set, the archive is
+// replayed from the start after its last record.
+func NewReplay(path string, speed float64, loop bool) *Replay {
+	return &Replay{
+		path:  path,
+		speed: speed,
+		loop:  loop,
+	}
+}
+
+// Endpoint returns the path of the archive.
+func (r *Replay) Endpoint() string {
+	return r.path
+}
+
+// Fetch returns the summary of the record that is due on the replay timeline.
+func (r *Replay) Fetch() (*types.Summary, *Metadata, error) {
+	start := time.Now()
+
+	record, err := r.due(start)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var summary types.Summary
+	if err := json.Unmarshal(record.Summary, &summary); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode recorded summary: %w", err)
+	}
+
+	meta := newMetadata(SourceReplay, r.path, start)
+	meta.FetchedAt = record.Timestamp.Add(r.offset)
+
+	return &summary, meta, nil
+}
+
+// Close closes the archive.
+func (r *Replay) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+
+	return r.reader.Close()
+}
+
+// due advances the archive to the record that is due at the given time.
+func (r *Replay) due(now time.Time) (*archive.Record, error) {
+	if r.done {
+		if !r.loop {
+			return nil, ErrReplayFinished
+		}
+
+		// start over from the first record, one recorded interval after the last one
+		if span := r.current.Timestamp.Sub(r.origin); r.records > 1 {
+			r.offset += span + span/time.Duration(r.records-1)
+		}
+
+		r.Close()
+		r.reader, r.current, r.next, r.done, r.records = nil, nil, nil, false, 0
+	}
+
+	if r.reader == nil {
+		if err := r.open(now); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.speed <= 0 {
+		if err := r.advance(); err != nil {
+			return nil, err
+		}
+	} else {
+		elapsed := time.Duration(float64(now.Sub(r.start)) * r.speed)
+		for r.next != nil && !r.next.Timestamp.After(r.origin.Add(elapsed)) {
+			if err := r.advance(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the last record is returned once
+	r.done = r.next == nil
+
+	return r.current, nil
+}
+
+// open opens the archive and reads its first record.
+func (r *Replay) open(now time.Time) error {
+	reader, err := archive.Open(r.path)
+	if err != nil {
+		return err
+	}
+
+	first, err := reader.Next()
+	if err != nil {
+		reader.Close()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("archive %s has no records", r.path)
+		}
+
+		return err
+	}
+
+	r.reader = reader
+	r.next = first
+	r.origin = first.Timestamp
+	r.start = now
+
+	return nil
+}
+
+// advance makes the next record the current one and reads the record after it.
+func (r *Replay) advance() error {
+	next, err := r.reader.Next()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	r.current, r.next = r.next, next
+	r.records++
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package sources
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/archive"
+)
+
+func TestReplayFetchedAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.gz")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	writer, err := archive.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 3 {
+		record := &archive.Record{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Endpoint:  "kubelet",
+			Summary:   []byte(`{"node": {"nodeName": "node"}, "pods": []}`),
+		}
+		if err := writer.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := NewReplay(path, 0, true)
+	defer replay.Close()
+
+	// the second loop continues one recorded interval after the last record
+	for i := range 6 {
+		_, meta, err := replay.Fetch()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := start.Add(time.Duration(i) * time.Minute); !meta.FetchedAt.Equal(want) {
+			t.Errorf("fetch %d: expected %s, got %s", i, want, meta.FetchedAt)
+		}
+	}
+}
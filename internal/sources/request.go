@@ -1,4 +1,4 @@
-package collector
+package sources
 
 import (
 	"net/http"
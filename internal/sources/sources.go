@@ -0,0 +1,56 @@
+package sources
+
+import (
+	"time"
+
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// constant values for the supported source names
+const (
+	SourceKubelet = "kubelet"
+	SourceCRI     = "cri"
+	SourceFile    = "file"
+	SourceProxy   = "proxy"
+	SourceReplay  = "replay"
+)
+
+// Metadata describes a single fetch of a summary.
+type Metadata struct {
+	Source    string
+	Endpoint  string
+	FetchedAt time.Time
+	Latency   time.Duration
+}
+
+// Source is implemented by every backend that can produce a storage usage summary
+// in the kubelet summary API format.
+type Source interface {
+	Endpoint() string
+	Fetch() (*types.Summary, *Metadata, error)
+}
+
+// StreamSource is implemented by the sources that can decode the summary pod by pod, so
+// the summary of a node with many pods is never held in memory at once. The pod passed
+// to the function is reused by the next call and must not be retained.
+type StreamSource interface {
+	Source
+	Stream(fn func(node *types.NodeSummary, pod *types.PodSummary)) (*Metadata, error)
+}
+
+// RawSource is implemented by the sources that can return the summary response as it
+// was received, before it is decoded. It is used to record the responses.
+type RawSource interface {
+	Source
+	FetchRaw() ([]byte, *Metadata, error)
+}
+
+// newMetadata creates the metadata of a fetch that started at the given time.
+func newMetadata(source, endpoint string, start time.Time) *Metadata {
+	return &Metadata{
+		Source:    source,
+		Endpoint:  endpoint,
+		FetchedAt: start,
+		Latency:   time.Since(start),
+	}
+}
@@ -0,0 +1,181 @@
+package top
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// constant values for the sort orders of the pods
+const (
+	SortEphemeral = "ephemeral"
+	SortInodes    = "inodes"
+	SortLogs      = "logs"
+	SortGrowth    = "growth"
+)
+
+// SortOrders lists the sort orders, in the order they are cycled.
+var SortOrders = []string{SortEphemeral, SortInodes, SortLogs, SortGrowth}
+
+// Pod holds the storage usage of a pod in the latest summary.
+type Pod struct {
+	Namespace string
+	Name      string
+
+	Used       uint64
+	Capacity   uint64
+	InodesUsed uint64
+	Inodes     uint64
+	Logs       uint64
+	// Growth is the change of the ephemeral used bytes per second since the previous summary.
+	Growth float64
+
+	Containers []types.ContainerSummary
+	Volumes    []types.VolumeSummary
+}
+
+// Key returns the namespace and name of the pod.
+func (p *Pod) Key() string {
+	return p.Namespace + "/" + p.Name
+}
+
+// usage is the ephemeral usage of a pod at a point in time, used for the growth rate.
+type usage struct {
+	used uint64
+	at   time.Time
+}
+
+// Model holds the state of the dashboard: the pods of the latest summary and the
+// selections of the user.
+type Model struct {
+	Node    string
+	Updated time.Time
+	Err     error
+
+	Sort      string
+	Namespace string
+	// Selected is the index of the selected pod in the visible list.
+	Selected int
+	// Detail is the key of the pod that is drilled down, if any.
+	Detail string
+
+	pods     []*Pod
+	previous map[string]usage
+}
+
+// NewModel creates an empty model with the sort order and the namespace filter.
+func NewModel(sort, namespace string) *Model {
+	if !slices.Contains(SortOrders, sort) {
+		sort = SortEphemeral
+	}
+
+	return &Model{
+		Sort:      sort,
+		Namespace: namespace,
+		previous:  make(map[string]usage),
+	}
+}
+
+// Update replaces the pods with the ones of the summary and computes their growth rates.
+func (m *Model) Update(summary *types.Summary, at time.Time) {
+	pods := make([]*Pod, 0, len(summary.Pods))
+	current := make(map[string]usage, len(summary.Pods))
+
+	for _, ps := range summary.Pods {
+		pod := &Pod{
+			Namespace:  ps.PodRef.Namespace,
+			Name:       ps.PodRef.Name,
+			Used:       ps.EphemeralStorage.UsedBytes,
+			Capacity:   ps.EphemeralStorage.CapacityBytes,
+			InodesUsed: ps.EphemeralStorage.InodesUsed,
+			Inodes:     ps.EphemeralStorage.Inodes,
+			Containers: ps.Containers,
+			Volumes:    ps.Volume,
+		}
+
+		for _, container := range ps.Containers {
+			pod.Logs += container.Logs.UsedBytes
+		}
+
+		// the growth rate needs the usage of the previous summary
+		if prev, ok := m.previous[pod.Key()]; ok && at.After(prev.at) {
+			pod.Growth = (float64(pod.Used) - float64(prev.used)) / at.Sub(prev.at).Seconds()
+		}
+
+		current[pod.Key()] = usage{used: pod.Used, at: at}
+		pods = append(pods, pod)
+	}
+
+	m.Node = summary.Node.NodeName
+	m.Updated = at
+	m.Err = nil
+	m.pods = pods
+	m.previous = current
+
+	m.Selected = min(m.Selected, max(len(m.Visible())-1, 0))
+}
+
+// Visible returns the pods of the filtered namespaces in the sort order.
+func (m *Model) Visible() []*Pod {
+	list := make([]*Pod, 0, len(m.pods))
+	for _, pod := range m.pods {
+		if m.Namespace == "" || strings.Contains(pod.Namespace, m.Namespace) {
+			list = append(list, pod)
+		}
+	}
+
+	slices.SortStableFunc(list, func(a, b *Pod) int {
+		var c int
+		switch m.Sort {
+		case SortInodes:
+			c = cmp.Compare(b.InodesUsed, a.InodesUsed)
+		case SortLogs:
+			c = cmp.Compare(b.Logs, a.Logs)
+		case SortGrowth:
+			c = cmp.Compare(b.Growth, a.Growth)
+		default:
+			c = cmp.Compare(b.Used, a.Used)
+		}
+
+		if c == 0 {
+			c = strings.Compare(a.Key(), b.Key())
+		}
+
+		return c
+	})
+
+	return list
+}
+
+// Pod returns the pod with the key, or nil if it is no longer in the summary.
+func (m *Model) Pod(key string) *Pod {
+	for _, pod := range m.pods {
+		if pod.Key() == key {
+			return pod
+		}
+	}
+
+	return nil
+}
+
+// NextSort cycles the sort order.
+func (m *Model) NextSort() {
+	i := slices.Index(SortOrders, m.Sort)
+	m.Sort = SortOrders[(i+1)%len(SortOrders)]
+}
+
+// Move moves the selection by the offset, within the visible pods.
+func (m *Model) Move(offset int) {
+	m.Selected = max(0, min(m.Selected+offset, len(m.Visible())-1))
+}
+
+// Open drills down into the selected pod.
+func (m *Model) Open() {
+	visible := m.Visible()
+	if m.Selected < len(visible) {
+		m.Detail = visible[m.Selected].Key()
+	}
+}
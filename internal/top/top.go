@@ -0,0 +1,214 @@
+package top
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/sources"
+	"github.com/amirhnajafiz/localsight/pkg/types"
+
+	"golang.org/x/term"
+)
+
+// constant values of the keys read from the terminal
+const (
+	keyUp = iota + 256
+	keyDown
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyCtrlC = 3
+)
+
+// Config holds the options of the dashboard.
+type Config struct {
+	Source    sources.Source
+	Interval  time.Duration
+	Sort      string
+	Namespace string
+}
+
+// result is a fetched summary or the fetch error.
+type result struct {
+	summary *types.Summary
+	err     error
+	at      time.Time
+}
+
+// Run shows the dashboard on the terminal until the user quits.
+func Run(cfg Config) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("top requires a terminal, use 'localsight once' to print the metrics")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set the terminal in raw mode: %w", err)
+	}
+
+	// use the alternate screen and restore the terminal on exit
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		_ = term.Restore(fd, state)
+	}()
+
+	keys := make(chan int)
+	go readKeys(os.Stdin, keys)
+
+	results := make(chan result)
+	refresh := make(chan struct{}, 1)
+	go poll(cfg, results, refresh)
+
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+
+	model := NewModel(cfg.Sort, cfg.Namespace)
+	model.Err = errors.New("waiting for the first summary")
+
+	// prompt holds the namespace filter while it is typed
+	var prompt *string
+
+	for {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 120, 40
+		}
+
+		text := ""
+		if prompt != nil {
+			text = *prompt
+		}
+		fmt.Print(Render(model, width, height, text))
+
+		select {
+		case <-resize:
+		case r := <-results:
+			if r.err != nil {
+				model.Err = r.err
+				continue
+			}
+
+			model.Update(r.summary, r.at)
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+
+			// edit the namespace filter
+			if prompt != nil {
+				switch key {
+				case keyEnter:
+					model.Namespace, prompt = *prompt, nil
+					model.Selected = 0
+				case keyEscape, keyCtrlC:
+					prompt = nil
+				case keyBackspace:
+					if len(*prompt) > 0 {
+						*prompt = (*prompt)[:len(*prompt)-1]
+					}
+				default:
+					if key >= ' ' && key < 127 {
+						*prompt += string(rune(key))
+					}
+				}
+
+				continue
+			}
+
+			switch key {
+			case 'q', keyCtrlC:
+				return nil
+			case keyUp, 'k':
+				model.Move(-1)
+			case keyDown, 'j':
+				model.Move(1)
+			case keyEnter:
+				model.Open()
+			case keyEscape, keyBackspace, 'h':
+				model.Detail = ""
+			case 's':
+				model.NextSort()
+			case 'e':
+				model.Sort = SortEphemeral
+			case 'i':
+				model.Sort = SortInodes
+			case 'l':
+				model.Sort = SortLogs
+			case 'g':
+				model.Sort = SortGrowth
+			case '/':
+				filter := model.Namespace
+				prompt = &filter
+			case 'r':
+				select {
+				case refresh <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// poll fetches a summary on every interval, or when a refresh is requested.
+func poll(cfg Config, results chan<- result, refresh <-chan struct{}) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		summary, _, err := cfg.Source.Fetch()
+		results <- result{summary: summary, err: err, at: time.Now()}
+
+		select {
+		case <-ticker.C:
+		case <-refresh:
+		}
+	}
+}
+
+// readKeys reads the keys from the terminal, decoding the arrow keys.
+func readKeys(f *os.File, keys chan<- int) {
+	defer close(keys)
+
+	reader := bufio.NewReader(f)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+
+		switch b {
+		case '\r', '\n':
+			keys <- keyEnter
+		case 127, 8:
+			keys <- keyBackspace
+		case 27:
+			// a lone escape is the escape key, otherwise it starts an arrow key sequence
+			if reader.Buffered() == 0 {
+				keys <- keyEscape
+				continue
+			}
+
+			if next, _ := reader.ReadByte(); next != '[' {
+				keys <- keyEscape
+				continue
+			}
+
+			switch code, _ := reader.ReadByte(); code {
+			case 'A':
+				keys <- keyUp
+			case 'B':
+				keys <- keyDown
+			}
+		default:
+			keys <- int(b)
+		}
+	}
+}
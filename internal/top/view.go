@@ -0,0 +1,198 @@
+package top
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// constant values of the ANSI escape sequences
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	reverse     = "\x1b[7m"
+	bold        = "\x1b[1m"
+	reset       = "\x1b[0m"
+)
+
+// Render draws the model as a screen of the given size. The prompt replaces the
+// footer while the user types a namespace filter.
+func Render(m *Model, width, height int, prompt string) string {
+	var lines []string
+
+	// header with the state of the dashboard
+	status := "updated " + m.Updated.Format(time.TimeOnly)
+	if m.Err != nil {
+		status = "error: " + m.Err.Error()
+	}
+
+	filter := "all namespaces"
+	if m.Namespace != "" {
+		filter = "namespace ~ " + m.Namespace
+	}
+
+	lines = append(lines,
+		bold+fit(fmt.Sprintf("localsight top - node %s - %s - sort by %s - %s", m.Node, filter, m.Sort, status), width)+reset,
+		"",
+	)
+
+	body := height - len(lines) - 1
+	if pod := m.Pod(m.Detail); m.Detail != "" && pod != nil {
+		lines = append(lines, detail(pod, width, body)...)
+	} else {
+		lines = append(lines, pods(m, width, body)...)
+	}
+
+	// pad the body, so the footer stays on the last line
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	footer := "q quit  j/k move  enter details  esc back  s sort  e/i/l/g sort by  / namespace  r refresh"
+	if prompt != "" {
+		footer = "namespace: " + prompt + "_"
+	}
+
+	lines = append(lines, reverse+fit(footer, width)+reset)
+
+	return clearScreen + strings.Join(lines, "\r\n")
+}
+
+// pods renders the table of the visible pods around the selected one.
+func pods(m *Model, width, height int) []string {
+	visible := m.Visible()
+
+	header := fmt.Sprintf("%-20s %-40s %10s %6s %10s %10s %12s", "NAMESPACE", "POD", "EPHEMERAL", "USE%", "INODES", "LOGS", "GROWTH/S")
+	lines := []string{reverse + fit(header, width) + reset}
+
+	// scroll the table to keep the selection in view
+	rows := max(height-1, 1)
+	first := max(0, m.Selected-rows+1)
+
+	for i := first; i < len(visible) && i < first+rows; i++ {
+		pod := visible[i]
+
+		line := fit(fmt.Sprintf(
+			"%-20s %-40s %10s %6s %10d %10s %12s",
+			truncate(pod.Namespace, 20),
+			truncate(pod.Name, 40),
+			formatBytes(pod.Used),
+			percent(pod.Used, pod.Capacity),
+			pod.InodesUsed,
+			formatBytes(pod.Logs),
+			formatRate(pod.Growth),
+		), width)
+
+		if i == m.Selected {
+			line = reverse + line + reset
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(visible) == 0 {
+		lines = append(lines, "no pods")
+	}
+
+	return lines
+}
+
+// detail renders the containers and the volumes of a pod.
+func detail(pod *Pod, width, height int) []string {
+	lines := []string{
+		bold + fit(fmt.Sprintf("%s  ephemeral %s of %s (%s), inodes %d of %d, growth %s/s",
+			pod.Key(), formatBytes(pod.Used), formatBytes(pod.Capacity), percent(pod.Used, pod.Capacity),
+			pod.InodesUsed, pod.Inodes, formatRate(pod.Growth)), width) + reset,
+		"",
+		reverse + fit(fmt.Sprintf("%-30s %10s %10s %10s %10s %10s", "CONTAINER", "ROOTFS", "INODES", "LOGS", "LOG INODES", "MEMORY"), width) + reset,
+	}
+
+	for _, c := range pod.Containers {
+		lines = append(lines, fit(fmt.Sprintf(
+			"%-30s %10s %10d %10s %10d %10s",
+			truncate(c.Name, 30),
+			formatBytes(c.Rootfs.UsedBytes),
+			c.Rootfs.InodesUsed,
+			formatBytes(c.Logs.UsedBytes),
+			c.Logs.InodesUsed,
+			formatBytes(c.Memory.UsageBytes),
+		), width))
+	}
+
+	lines = append(lines,
+		"",
+		reverse+fit(fmt.Sprintf("%-30s %10s %10s %6s %10s %10s", "VOLUME", "USED", "CAPACITY", "USE%", "AVAILABLE", "INODES"), width)+reset,
+	)
+
+	for _, v := range pod.Volumes {
+		lines = append(lines, fit(fmt.Sprintf(
+			"%-30s %10s %10s %6s %10s %10d",
+			truncate(v.Name, 30),
+			formatBytes(v.UsedBytes),
+			formatBytes(v.CapacityBytes),
+			percent(v.UsedBytes, v.CapacityBytes),
+			formatBytes(v.AvailableBytes),
+			v.InodesUsed,
+		), width))
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	return lines
+}
+
+// fit pads or cuts the line to the width of the screen.
+func fit(line string, width int) string {
+	if len(line) > width {
+		return line[:width]
+	}
+
+	return line + strings.Repeat(" ", width-len(line))
+}
+
+// truncate cuts the value to the size of its column.
+func truncate(value string, size int) string {
+	if len(value) <= size {
+		return value
+	}
+
+	return value[:size-1] + "~"
+}
+
+// formatBytes returns the bytes in binary units, e.g. 1.5Gi.
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+
+	value, exp := float64(bytes), 0
+	for value >= unit && exp < 5 {
+		value /= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ci", value, "KMGTP"[exp-1])
+}
+
+// formatRate returns the signed bytes per second of a growth rate.
+func formatRate(rate float64) string {
+	switch {
+	case rate > 0:
+		return "+" + formatBytes(uint64(rate))
+	case rate < 0:
+		return "-" + formatBytes(uint64(-rate))
+	default:
+		return "0"
+	}
+}
+
+// percent returns the used fraction of the capacity.
+func percent(used, capacity uint64) string {
+	if capacity == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f", 100*float64(used)/float64(capacity))
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/history"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// node is the storage breakdown of a node, as served to the page.
+type node struct {
+	Name        string        `json:"name"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Up          bool          `json:"up"`
+	Filesystems []*filesystem `json:"filesystems"`
+	Pods        []*pod        `json:"pods"`
+}
+
+// filesystem is a node file system, known from the capacity of the pod ephemeral
+// storage (nodefs) and of the container root file systems (imagefs).
+type filesystem struct {
+	Name      string  `json:"name"`
+	Capacity  float64 `json:"capacity"`
+	Available float64 `json:"available"`
+}
+
+// pod is the storage usage of a pod and its parts.
+type pod struct {
+	Namespace  string          `json:"namespace"`
+	Name       string          `json:"name"`
+	Used       float64         `json:"used"`
+	Containers []*part         `json:"containers"`
+	Volumes    []*part         `json:"volumes"`
+	History    []history.Point `json:"history"`
+	index      map[string]*part
+}
+
+// part is a container or a volume of a pod.
+type part struct {
+	Name   string  `json:"name"`
+	Rootfs float64 `json:"rootfs"`
+	Logs   float64 `json:"logs"`
+	Used   float64 `json:"used"`
+}
+
+// newNode builds the storage breakdown of a node from its snapshot, with the history
+// of each pod returned by the sparkline function.
+func newNode(snapshot *metrics.Snapshot, sparkline func(labels []string) []history.Point) *node {
+	n := &node{Name: snapshot.Node, Timestamp: snapshot.Timestamp}
+	nodefs := &filesystem{Name: "nodefs"}
+	imagefs := &filesystem{Name: "imagefs"}
+	pods := make(map[string]*pod)
+
+	// get returns the pod of the sample labels, creating it on the first sample
+	get := func(labels []string) *pod {
+		key := labels[1] + "/" + labels[0]
+		p, ok := pods[key]
+		if !ok {
+			p = &pod{Namespace: labels[1], Name: labels[0], History: sparkline(labels[:3]), index: make(map[string]*part)}
+			pods[key] = p
+			n.Pods = append(n.Pods, p)
+		}
+
+		return p
+	}
+
+	for _, sample := range snapshot.Samples {
+		labels := sample.LabelValues
+
+		switch sample.Definition {
+		case metrics.APIStatus:
+			n.Up = sample.Value == 1
+		case metrics.EphemeralStorageUsageBytes:
+			get(labels).Used = sample.Value
+		case metrics.EphemeralStorageCapacityBytes:
+			nodefs.Capacity = max(nodefs.Capacity, sample.Value)
+		case metrics.EphemeralStorageAvailableBytes:
+			nodefs.Available = max(nodefs.Available, sample.Value)
+		case metrics.ContainerRootfsCapacityBytes:
+			imagefs.Capacity = max(imagefs.Capacity, sample.Value)
+		case metrics.ContainerRootfsAvailableBytes:
+			imagefs.Available = max(imagefs.Available, sample.Value)
+		case metrics.ContainerRootfsUsageBytes:
+			get(labels).container(labels[3]).Rootfs = sample.Value
+		case metrics.ContainerLogsUsageBytes:
+			get(labels).container(labels[3]).Logs = sample.Value
+		case metrics.PodVolumeUsageBytes:
+			p := get(labels)
+			p.Volumes = append(p.Volumes, &part{Name: labels[3], Used: sample.Value})
+		}
+	}
+
+	for _, fs := range []*filesystem{nodefs, imagefs} {
+		if fs.Capacity > 0 {
+			n.Filesystems = append(n.Filesystems, fs)
+		}
+	}
+
+	slices.SortFunc(n.Pods, func(a, b *pod) int {
+		return cmp.Compare(b.Used, a.Used)
+	})
+
+	return n
+}
+
+// container returns the container part of the pod, creating it on the first sample.
+func (p *pod) container(name string) *part {
+	c, ok := p.index[name]
+	if !ok {
+		c = &part{Name: name}
+		p.index[name] = c
+		p.Containers = append(p.Containers, c)
+	}
+
+	return c
+}
+
+// sortNodes sorts the nodes by name.
+func sortNodes(list []*node) {
+	slices.SortFunc(list, func(a, b *node) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
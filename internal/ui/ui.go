@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/history"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+//go:embed static
+var static embed.FS
+
+// sparklinePoints is the number of points of the pod sparklines.
+const sparklinePoints = 60
+
+// UI serves a web page that visualizes the published snapshots, with the pod
+// ephemeral usage history for the sparklines when a history store is set.
+type UI struct {
+	metrics *metrics.Metrics
+	history *history.Store
+}
+
+// New creates a UI over the published snapshots and the optional history store.
+func New(mtx *metrics.Metrics, store *history.Store) *UI {
+	return &UI{
+		metrics: mtx,
+		history: store,
+	}
+}
+
+// Register adds the page and its API to the mux, under /ui/.
+func (u *UI) Register(mux *http.ServeMux) {
+	files, _ := fs.Sub(static, "static")
+
+	mux.Handle("GET /ui/", http.StripPrefix("/ui/", http.FileServerFS(files)))
+	mux.HandleFunc("GET /ui/api/nodes", u.nodes)
+}
+
+// nodes responds with the storage breakdown of every node.
+func (u *UI) nodes(w http.ResponseWriter, _ *http.Request) {
+	snapshots := u.metrics.Snapshots()
+
+	list := make([]*node, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		list = append(list, newNode(snapshot, u.sparkline))
+	}
+
+	sortNodes(list)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// sparkline returns the latest ephemeral usage points of a pod.
+func (u *UI) sparkline(labels []string) []history.Point {
+	if u.history == nil {
+		return nil
+	}
+
+	points := u.history.Points(metrics.EphemeralStorageUsageBytes, labels, time.Time{})
+	if len(points) > sparklinePoints {
+		points = points[len(points)-sparklinePoints:]
+	}
+
+	return points
+}
@@ -1,66 +1,84 @@
 package main
 
 import (
-	"time"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
 
-	"github.com/amirhnajafiz/localsight/internal/collector"
 	"github.com/amirhnajafiz/localsight/internal/configs"
-	"github.com/amirhnajafiz/localsight/internal/logr"
-	"github.com/amirhnajafiz/localsight/internal/metrics"
-
-	"go.uber.org/zap"
 )
 
-func main() {
-	// load the configuration from the environment variables
-	conf, err := configs.LoadConfig()
-	if err != nil {
-		panic(err)
-	}
+// usage is printed for the help command and unknown commands.
+const usage = `usage: localsight <command> [flags]
 
-	// convert the interval
-	interval, err := time.ParseDuration(conf.Interval)
-	if err != nil {
-		panic(err)
-	}
+commands:
+  serve      run the exporter (default)
+  once       collect a single summary, print the metrics and exit
+  check      verify the summary source connectivity and credentials
+  top        show a live dashboard of the node storage usage
+  record     save the summaries to an archive for the replay source
+  generate   generate the alerting rules or the Grafana dashboard
+  version    print the version
 
-	// initialize a zap logger
-	logger := logr.NewZapLogger(conf.Debug, conf.JSONLog)
+run 'localsight <command> -h' for the flags of a command.
+`
 
-	// print config values
-	logger.Info(
-		"config",
-		zap.Int("port", conf.Port),
-		zap.Bool("debug", conf.Debug),
-		zap.Bool("json", conf.JSONLog),
-		zap.String("interval", conf.Interval),
-		zap.String("node", conf.NodeName),
-		zap.String("cert", conf.CertFile),
-		zap.String("key", conf.KeyFile),
-	)
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		// the usage of the flags is already printed
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
 
-	// create a new metrics instance
-	mtx, err := metrics.NewMetrics()
-	if err != nil {
-		logger.Fatal("failed to create metrics instance", zap.Error(err))
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+}
 
-	// start the metrics server on port 8080
-	metrics.StartMetricsServer(logger.Named("metrics-server"), conf.Port)
+// run dispatches the subcommand, and runs the exporter when no command is given.
+func run(args []string) error {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	// create a new collector instance with the metrics
-	col := &collector.Collector{
-		NodeName: conf.NodeName,
-		CertFile: conf.CertFile,
-		KeyFile:  conf.KeyFile,
-		EndPoint: conf.K8SLocalAPI,
-		Logr:     logger.Named("collector"),
-		Metrics:  mtx,
-		Interval: interval,
+	switch command {
+	case "serve":
+		return runServe(args)
+	case "once":
+		return runOnce(args)
+	case "check":
+		return runCheck(args)
+	case "top":
+		return runTop(args)
+	case "record":
+		return runRecord(args)
+	case "generate":
+		return runGenerate(args)
+	case "version":
+		return runVersion(args)
+	case "help":
+		fmt.Print(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command: %s\n\n%s", command, usage)
 	}
+}
 
-	// start the collector to fetch and update metrics
-	if err := col.Start(); err != nil {
-		logger.Fatal("failed to start collector", zap.Error(err))
+// parseConfig registers the config flags on the flag set of a command, parses the
+// arguments and loads the configuration.
+func parseConfig(fs *flag.FlagSet, args []string) (*configs.Config, *configs.Flags, error) {
+	flags := configs.NewFlags(fs, os.Getenv("LSE_CONFIG_FILE"))
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
 	}
+
+	conf, err := configs.LoadConfig(flags.Path, flags.Values())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conf, flags, nil
 }
@@ -1,40 +1,100 @@
 package main
 
 import (
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/fakekubelet"
 )
 
+// a fake kubelet that serves the summary API of a generated node, with the scripted
+// behaviors of a scenario file.
 func main() {
-	http.HandleFunc("/stats/summary", func(w http.ResponseWriter, r *http.Request) {
-		f, err := os.Open("mock/res.json")
+	addr := flag.String("addr", ":10250", "listen address")
+	scenarioFile := flag.String("scenario", "", "scenario file, a node of 10 pods without behaviors when empty")
+	pods := flag.Int("pods", 0, "number of pods, overrides the scenario")
+	certFile := flag.String("cert", "", "server certificate file, a self-signed certificate when empty")
+	keyFile := flag.String("key", "", "server key file")
+	clientCA := flag.String("client-ca", "", "CA file of the accepted client certificates")
+	token := flag.String("token", "", "accepted bearer token")
+	flag.Parse()
+
+	cfg := fakekubelet.Config{Token: *token}
+
+	cfg.Scenario = fakekubelet.DefaultScenario()
+	if *scenarioFile != "" {
+		scenario, err := fakekubelet.LoadScenario(*scenarioFile)
 		if err != nil {
-			http.Error(w, "could not open res.json", http.StatusInternalServerError)
-			return
+			log.Fatal(err)
 		}
-		defer f.Close()
 
-		w.Header().Set("Content-Type", "application/json")
-		if _, err := w.Write([]byte{'\n'}); err != nil {
-			log.Println("error writing newline:", err)
+		cfg.Scenario = scenario
+	}
+
+	if *pods > 0 {
+		cfg.Scenario.Pods = *pods
+	}
+
+	if *certFile != "" {
+		cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+		if err != nil {
+			log.Fatal("could not load server certificate: ", err)
 		}
-		if err := json.NewEncoder(w).Encode(json.RawMessage(mustReadAll(f))); err != nil {
-			http.Error(w, "could not encode json", http.StatusInternalServerError)
+
+		cfg.Certificate = &cert
+	}
+
+	if *clientCA != "" {
+		data, err := os.ReadFile(*clientCA)
+		if err != nil {
+			log.Fatal("could not read client CA: ", err)
 		}
 
-		log.Println("Handled /stats/summary request")
-	})
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(data) {
+			log.Fatal("no certificates in client CA file ", *clientCA)
+		}
+	}
 
-	log.Println("Mock kubelet API server running on :10250")
-	log.Fatal(http.ListenAndServeTLS(":10250", "mock/server.crt", "mock/server.key", nil))
-}
+	server, err := fakekubelet.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-func mustReadAll(f *os.File) []byte {
-	data, err := os.ReadFile(f.Name())
+	l, err := net.Listen("tcp", *addr)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return data
+
+	log.Printf("Fake kubelet of node %s with %d pods running on %s\n", cfg.Scenario.NodeName, cfg.Scenario.Pods, l.Addr())
+	log.Fatal(http.Serve(tls.NewListener(l, server.TLSConfig()), logRequests(server.Handler())))
+}
+
+// logRequests logs the status and the latency of every request.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		log.Printf("Handled %s %s: %d in %s\n", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// statusRecorder keeps the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/amirhnajafiz/localsight/internal/sinks/remotewrite"
+
+	"github.com/golang/snappy"
+)
+
+// a stand-in Prometheus remote write receiver that logs the received series.
+func main() {
+	http.HandleFunc("/api/v1/write", func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			http.Error(w, "could not decompress body", http.StatusBadRequest)
+			return
+		}
+
+		series, err := remotewrite.Decode(data)
+		if err != nil {
+			http.Error(w, "could not decode write request", http.StatusBadRequest)
+			return
+		}
+
+		for _, s := range series {
+			log.Println(s.Labels, s.Samples)
+		}
+
+		log.Printf("Handled /api/v1/write request with %d series\n", len(series))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Println("Mock remote write receiver running on :9090")
+	log.Fatal(http.ListenAndServe(":9090", nil))
+}
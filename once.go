@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/amirhnajafiz/localsight/internal/collector"
+	"github.com/amirhnajafiz/localsight/internal/configs"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+	"github.com/amirhnajafiz/localsight/internal/sinks/jsonl"
+)
+
+// constant values for the output formats of the once command
+const (
+	outputPrometheus = "prometheus"
+	outputJSON       = "json"
+)
+
+// runOnce collects a single summary of the node, prints its metrics and exits.
+func runOnce(args []string) error {
+	fs := flag.NewFlagSet("once", flag.ContinueOnError)
+	output := fs.String("output", outputPrometheus, "output format: prometheus or json")
+
+	conf, _, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if conf.Mode != configs.ModeNode {
+		return fmt.Errorf("once collects a single node, use --source proxy --node-name <node> to collect a node through the API server")
+	}
+
+	src, err := newSource(conf)
+	if err != nil {
+		return fmt.Errorf("failed to create summary source: %w", err)
+	}
+
+	col := &collector.Collector{
+		NodeName: conf.NodeName,
+		Source:   src,
+		Options:  collector.NewOptions(settings(conf)),
+	}
+
+	snapshot, _, err := col.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to get summary: %w", err)
+	}
+
+	switch *output {
+	case outputPrometheus:
+		return metrics.WriteText(os.Stdout, snapshot)
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(jsonl.Records(snapshot))
+	default:
+		return fmt.Errorf("unknown output format: %s", *output)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+
+	"github.com/amirhnajafiz/localsight/pkg/types"
 )
 
 // GET performs an HTTP GET request using the provided request object.
@@ -49,3 +52,24 @@ func JSON(resp *http.Response, v any) error {
 
 	return nil
 }
+
+// Body reads the raw body of the provided HTTP response object.
+func Body(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Summary decodes the summary in the provided HTTP response object pod by pod, and calls
+// the function with every pod as soon as it is decoded.
+func Summary(resp *http.Response, fn func(node *types.NodeSummary, pod *types.PodSummary)) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return types.StreamSummary(resp.Body, fn)
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamSummary decodes a summary from the reader and calls the function with every pod
+// as soon as it is decoded, so the pods of a large node are never held in memory at once.
+// The pod is reused by the next call and must not be retained. The kubelet writes the node
+// before the pods, and pods that come before the node are held until it is decoded.
+func StreamSummary(r io.Reader, fn func(node *NodeSummary, pod *PodSummary)) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	var (
+		node    NodeSummary
+		hasNode bool
+		pending []PodSummary
+		pod     PodSummary
+	)
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case "node":
+			if err := dec.Decode(&node); err != nil {
+				return fmt.Errorf("failed to decode node: %w", err)
+			}
+			hasNode = true
+
+			for i := range pending {
+				fn(&node, &pending[i])
+			}
+			pending = nil
+		case "pods":
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			// a node without pods may have a null list
+			if token == nil {
+				continue
+			}
+			if token != json.Delim('[') {
+				return fmt.Errorf("expected pods array, got %v", token)
+			}
+
+			for dec.More() {
+				reset(&pod)
+				if err := dec.Decode(&pod); err != nil {
+					return fmt.Errorf("failed to decode pod: %w", err)
+				}
+
+				if !hasNode {
+					pending = append(pending, clonePod(pod))
+					continue
+				}
+
+				fn(&node, &pod)
+			}
+
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		default:
+			// skip the fields that are not collected
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	// the pods of a summary without a node
+	for i := range pending {
+		fn(&node, &pending[i])
+	}
+
+	return nil
+}
+
+// expectDelim reads the next token and checks that it is the delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+
+	return nil
+}
+
+// reset clears the pod and keeps the arrays of its containers and volumes for the next
+// pod. The elements are cleared, since the decoder keeps the fields a pod does not have.
+func reset(pod *PodSummary) {
+	containers := pod.Containers[:cap(pod.Containers)]
+	volumes := pod.Volume[:cap(pod.Volume)]
+	clear(containers)
+	clear(volumes)
+
+	*pod = PodSummary{
+		Containers: containers[:0],
+		Volume:     volumes[:0],
+	}
+}
+
+// clonePod copies the pod with its own containers and volumes.
+func clonePod(pod PodSummary) PodSummary {
+	pod.Containers = append([]ContainerSummary(nil), pod.Containers...)
+	pod.Volume = append([]VolumeSummary(nil), pod.Volume...)
+
+	return pod
+}
@@ -0,0 +1,137 @@
+package types_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amirhnajafiz/localsight/internal/fakekubelet"
+	"github.com/amirhnajafiz/localsight/pkg/types"
+)
+
+// largeSummary returns the summary JSON of a node with 400 pods, from the fake kubelet.
+func largeSummary(tb testing.TB) []byte {
+	tb.Helper()
+
+	scenario := fakekubelet.DefaultScenario()
+	scenario.Pods = 400
+	scenario.Containers = 3
+	scenario.Volumes = 2
+
+	server, err := fakekubelet.New(fakekubelet.Config{Scenario: scenario})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/stats/summary", nil))
+
+	return rec.Body.Bytes()
+}
+
+func TestStreamSummary(t *testing.T) {
+	data := largeSummary(t)
+
+	var want types.Summary
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	var got types.Summary
+	err := types.StreamSummary(bytes.NewReader(data), func(node *types.NodeSummary, pod *types.PodSummary) {
+		got.Node = *node
+
+		// the pod is reused, so the test keeps a copy
+		clone := *pod
+		clone.Containers = append([]types.ContainerSummary(nil), pod.Containers...)
+		clone.Volume = append([]types.VolumeSummary(nil), pod.Volume...)
+		got.Pods = append(got.Pods, clone)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Error("streamed summary differs from the decoded summary")
+	}
+}
+
+func TestStreamSummaryOrder(t *testing.T) {
+	// the pods before the node, a pod without volumes after a pod with volumes, and
+	// the fields that are not collected
+	data := `{
+		"pods": [
+			{"podRef": {"name": "a"}, "volume": [{"name": "data", "usedBytes": 10}]},
+			{"podRef": {"name": "b"}, "containers": [{"name": "app"}]}
+		],
+		"extra": {"ignored": [1, 2, 3]},
+		"node": {"nodeName": "n1", "fs": {"usedBytes": 1}}
+	}`
+
+	var pods []string
+	err := types.StreamSummary(strings.NewReader(data), func(node *types.NodeSummary, pod *types.PodSummary) {
+		if node.NodeName != "n1" {
+			t.Errorf("pod %s streamed with node %q", pod.PodRef.Name, node.NodeName)
+		}
+
+		if pod.PodRef.Name == "b" && len(pod.Volume) != 0 {
+			t.Errorf("pod b has the volumes of pod a: %v", pod.Volume)
+		}
+
+		pods = append(pods, pod.PodRef.Name)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(pods, []string{"a", "b"}) {
+		t.Errorf("expected pods [a b], got %v", pods)
+	}
+
+	if err := types.StreamSummary(strings.NewReader(`{"node": {}, "pods": [{`), func(*types.NodeSummary, *types.PodSummary) {}); err == nil {
+		t.Error("expected an error for a truncated summary")
+	}
+}
+
+// BenchmarkDecodeSummary decodes the whole summary before its pods are processed, like
+// a json.Decoder on the response body, which buffers the whole body first.
+func BenchmarkDecodeSummary(b *testing.B) {
+	data := largeSummary(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var summary types.Summary
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&summary); err != nil {
+			b.Fatal(err)
+		}
+
+		for i := range summary.Pods {
+			process(&summary.Node, &summary.Pods[i])
+		}
+	}
+}
+
+// BenchmarkStreamSummary processes the pods of the summary one by one as they are decoded.
+func BenchmarkStreamSummary(b *testing.B) {
+	data := largeSummary(b)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if err := types.StreamSummary(bytes.NewReader(data), process); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// used keeps the processed values, so the processing is not optimized away.
+var used uint64
+
+// process stands in for the collector processing a pod.
+func process(_ *types.NodeSummary, pod *types.PodSummary) {
+	used += pod.EphemeralStorage.UsedBytes
+}
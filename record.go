@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/archive"
+	"github.com/amirhnajafiz/localsight/internal/configs"
+	"github.com/amirhnajafiz/localsight/internal/sources"
+)
+
+// runRecord saves the summary responses of the source with their timestamps to a
+// compressed archive, on every interval, until it is interrupted or the limits are reached.
+// The archive is replayed with the replay source.
+func runRecord(args []string) error {
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	path := fs.String("archive", "", "archive file the summaries are written to")
+	count := fs.Int("count", 0, "number of summaries recorded, 0 records until interrupted")
+	duration := fs.Duration("duration", 0, "period of the recording, 0 records until interrupted")
+
+	conf, _, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return fmt.Errorf("record requires --archive")
+	}
+
+	if conf.Mode != configs.ModeNode {
+		return fmt.Errorf("record saves a single node, use --source proxy --node-name <node> to record a node through the API server")
+	}
+
+	src, err := newSource(conf)
+	if err != nil {
+		return fmt.Errorf("failed to create summary source: %w", err)
+	}
+	if closer, ok := src.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	w, err := archive.Create(*path)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	recorded := 0
+loop:
+	for {
+		record, err := fetchRecord(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping summary: %v\n", err)
+		} else {
+			if err := w.Write(record); err != nil {
+				w.Close()
+				return err
+			}
+
+			recorded++
+			fmt.Fprintf(os.Stderr, "recorded summary %d at %s (%d bytes)\n", recorded, record.Timestamp.Format(time.RFC3339), len(record.Summary))
+		}
+
+		if *count > 0 && recorded >= *count {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-time.After(conf.Interval):
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "wrote %d summaries to %s\n", recorded, *path)
+
+	return nil
+}
+
+// fetchRecord fetches a summary from the source as it was received. The summaries of the
+// sources without a raw response, like the CRI source, are recorded in the summary format.
+func fetchRecord(src sources.Source) (*archive.Record, error) {
+	if raw, ok := src.(sources.RawSource); ok {
+		data, meta, err := raw.FetchRaw()
+		if err != nil {
+			return nil, err
+		}
+
+		if !json.Valid(data) {
+			return nil, errors.New("summary response is not valid JSON")
+		}
+
+		return &archive.Record{Timestamp: meta.FetchedAt, Endpoint: meta.Endpoint, Summary: data}, nil
+	}
+
+	summary, meta, err := src.Fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode summary: %w", err)
+	}
+
+	return &archive.Record{Timestamp: meta.FetchedAt, Endpoint: meta.Endpoint, Summary: data}, nil
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/alerts"
+	"github.com/amirhnajafiz/localsight/internal/anomaly"
+	"github.com/amirhnajafiz/localsight/internal/checkpoint"
+	"github.com/amirhnajafiz/localsight/internal/collector"
+	"github.com/amirhnajafiz/localsight/internal/configs"
+	"github.com/amirhnajafiz/localsight/internal/history"
+	"github.com/amirhnajafiz/localsight/internal/kube"
+	"github.com/amirhnajafiz/localsight/internal/logr"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+	"github.com/amirhnajafiz/localsight/internal/otlp"
+	"github.com/amirhnajafiz/localsight/internal/sharding"
+	"github.com/amirhnajafiz/localsight/internal/sinks"
+	"github.com/amirhnajafiz/localsight/internal/sinks/events"
+	"github.com/amirhnajafiz/localsight/internal/sinks/influx"
+	"github.com/amirhnajafiz/localsight/internal/sinks/jsonl"
+	"github.com/amirhnajafiz/localsight/internal/sinks/remotewrite"
+	"github.com/amirhnajafiz/localsight/internal/sinks/statsd"
+	"github.com/amirhnajafiz/localsight/internal/sources"
+	"github.com/amirhnajafiz/localsight/internal/ui"
+
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout bounds the time to close the sinks, within the default termination
+// grace period of a pod.
+const shutdownTimeout = 20 * time.Second
+
+// runServe runs the exporter until it is stopped.
+func runServe(args []string) error {
+	conf, flags, err := parseConfig(flag.NewFlagSet("serve", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+
+	// initialize a zap logger
+	logger := logr.NewZapLogger(conf.Debug, conf.JSONLog)
+
+	// run until the pod is stopped, then save the state and close the sinks
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// print config values
+	logger.Info(
+		"config",
+		zap.Int("port", conf.Port),
+		zap.Bool("debug", conf.Debug),
+		zap.Bool("json", conf.JSONLog),
+		zap.Duration("interval", conf.Interval),
+		zap.String("node", conf.NodeName),
+		zap.String("cert", conf.CertFile),
+		zap.String("key", conf.KeyFile),
+		zap.String("source", conf.Source),
+		zap.String("mode", conf.Mode),
+	)
+
+	// create a new metrics instance
+	mtx, err := metrics.NewMetrics()
+	if err != nil {
+		logger.Fatal("failed to create metrics instance", zap.Error(err))
+	}
+
+	// start the metrics server on port 8080
+	metrics.StartMetricsServer(logger.Named("metrics-server"), conf.Port)
+
+	// push the metrics to an OpenTelemetry collector when an endpoint is set
+	var exporter *otlp.Exporter
+	if conf.OTLPAddress != "" {
+		exporter, err = otlp.NewExporter(logger.Named("otlp"), otlp.Config{
+			Endpoint: conf.OTLPAddress,
+			Protocol: conf.OTLPProto,
+			Insecure: conf.OTLPNoTLS,
+			Interval: conf.Interval,
+			NodeName: conf.NodeName,
+			PodName:  conf.PodName,
+		}, mtx)
+		if err != nil {
+			logger.Fatal("failed to create OTLP exporter", zap.Error(err))
+		}
+	}
+
+	// create the output sinks that receive the snapshot of each cycle
+	outputs, err := newSinks(conf, logger)
+	if err != nil {
+		logger.Fatal("failed to create output sinks", zap.Error(err))
+	}
+
+	// keep the recent samples of every series for the history API
+	published := []sinks.Sink{mtx}
+
+	var store *history.Store
+	if conf.HistoryRetention > 0 {
+		store = history.NewStore(conf.HistoryRetention, conf.Interval, conf.HistorySeries)
+		store.Register(http.DefaultServeMux)
+
+		published = append(published, store)
+	}
+
+	// serve the web UI with the metrics
+	if conf.UI {
+		ui.New(mtx, store).Register(http.DefaultServeMux)
+	}
+
+	// score the growth of the pod ephemeral storage against its moving average
+	var detector *anomaly.Detector
+	if conf.Anomaly {
+		detector = anomaly.NewDetector(logger.Named("anomaly"), anomaly.Config{
+			Alpha:     conf.AnomalyAlpha,
+			Threshold: conf.AnomalyThreshold,
+			Warmup:    conf.AnomalyWarmup,
+		})
+	}
+
+	// restore the state of the previous run, and save it periodically
+	var cp *checkpoint.Checkpointer
+	if conf.CheckpointFile != "" {
+		var components []checkpoint.Component
+		if store != nil {
+			components = append(components, store)
+		}
+		if detector != nil {
+			components = append(components, detector)
+		}
+		for _, sink := range outputs {
+			if engine, ok := sink.(*alerts.Engine); ok {
+				components = append(components, engine)
+			}
+		}
+
+		cp = startCheckpoints(ctx, conf, components, logger.Named("checkpoint"))
+	}
+
+	fanout := sinks.NewFanOut(logger.Named("sinks"), mtx, conf.SinkTimeout, append(published, outputs...)...)
+
+	// apply the reloadable options when the configuration changes
+	options := collector.NewOptions(settings(conf))
+	go configs.Watch(flags.Path, flags.Values(), 10*time.Second, logger.Named("configs"), conf, func(next *configs.Config) error {
+		return reload(next, options, outputs, logger)
+	})
+
+	// in cluster mode, a single instance collects every node through the API server
+	if conf.Mode == configs.ModeCluster {
+		client, err := kube.NewInCluster(conf.APIServer)
+		if err != nil {
+			logger.Fatal("failed to create API server client", zap.Error(err))
+		}
+
+		// divide the nodes between the replicas when sharding is enabled
+		var registry *sharding.Registry
+		if conf.Sharding {
+			registry = &sharding.Registry{
+				Client:        client,
+				Namespace:     conf.Namespace,
+				Identity:      conf.PodName,
+				LeaseDuration: conf.LeaseTTL,
+				Logr:          logger.Named("sharding"),
+			}
+		}
+
+		col := &collector.ClusterCollector{
+			Client:   client,
+			Registry: registry,
+			Workers:  conf.Workers,
+			Logr:     logger.Named("cluster-collector"),
+			Metrics:  mtx,
+			Sinks:    fanout,
+			Options:  options,
+			Detector: detector,
+		}
+
+		if err := col.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start cluster collector: %w", err)
+		}
+
+		shutdown(cp, fanout, exporter, logger)
+
+		return nil
+	}
+
+	// create the summary source
+	src, err := newSource(conf)
+	if err != nil {
+		logger.Fatal("failed to create summary source", zap.Error(err))
+	}
+
+	// create a new collector instance with the output sinks
+	col := &collector.Collector{
+		NodeName: conf.NodeName,
+		Source:   src,
+		Logr:     logger.Named("collector"),
+		Sinks:    fanout,
+		Options:  options,
+		Detector: detector,
+	}
+
+	// start the collector to fetch and update metrics
+	if err := col.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start collector: %w", err)
+	}
+
+	shutdown(cp, fanout, exporter, logger)
+
+	return nil
+}
+
+// startCheckpoints restores the state of the components from the checkpoint file,
+// and saves it on every interval until the context is done.
+func startCheckpoints(ctx context.Context, conf *configs.Config, components []checkpoint.Component, logger *zap.Logger) *checkpoint.Checkpointer {
+	cp := &checkpoint.Checkpointer{
+		Path:       conf.CheckpointFile,
+		Interval:   conf.CheckpointInterval,
+		Components: components,
+		Logr:       logger,
+	}
+
+	cp.Restore()
+	go cp.Start(ctx)
+
+	return cp
+}
+
+// shutdown saves the latest state to the checkpoint and closes the output sinks in
+// order, once the collector has stopped, e.g. the remote write queue is drained before
+// the JSON lines file is closed. The OTLP exporter pushes the last snapshots and stops.
+func shutdown(cp *checkpoint.Checkpointer, fanout *sinks.FanOut, exporter *otlp.Exporter, logger *zap.Logger) {
+	logger.Info("stopping exporter")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if cp != nil {
+		if err := cp.Save(); err != nil {
+			logger.Error("failed to save checkpoint", zap.Error(err))
+		}
+	}
+
+	if err := fanout.Close(ctx); err != nil {
+		logger.Error("failed to close output sinks", zap.Error(err))
+	}
+
+	if exporter != nil {
+		if err := exporter.Shutdown(ctx); err != nil {
+			logger.Error("failed to shut down OTLP exporter", zap.Error(err))
+		}
+	}
+}
+
+// newSource creates the summary source selected in the configuration.
+func newSource(conf *configs.Config) (sources.Source, error) {
+	switch conf.Source {
+	case sources.SourceKubelet:
+		return sources.NewKubelet(conf.K8SLocalAPI, conf.CertFile, conf.KeyFile)
+	case sources.SourceCRI:
+		return sources.NewCRI(conf.NodeName, conf.CRIEndpoint, conf.Interval)
+	case sources.SourceFile:
+		return sources.NewFile(conf.SourceFile), nil
+	case sources.SourceReplay:
+		return sources.NewReplay(conf.SourceFile, conf.ReplaySpeed, conf.ReplayLoop), nil
+	case sources.SourceProxy:
+		client, err := kube.NewInCluster(conf.APIServer)
+		if err != nil {
+			return nil, err
+		}
+
+		return sources.NewProxy(client, conf.NodeName), nil
+	default:
+		return nil, fmt.Errorf("unknown source: %s", conf.Source)
+	}
+}
+
+// newSinks creates the output sinks enabled in the configuration.
+func newSinks(conf *configs.Config, logger *zap.Logger) ([]sinks.Sink, error) {
+	var list []sinks.Sink
+
+	// push the samples to a remote write endpoint
+	if conf.RWURL != "" {
+		list = append(list, remotewrite.NewClient(logger.Named("remote-write"), remotewrite.Config{
+			URL:         conf.RWURL,
+			Username:    conf.RWUsername,
+			Password:    conf.RWPassword,
+			BearerToken: conf.RWToken,
+			QueueSize:   conf.RWQueueSize,
+			MaxRetries:  conf.RWRetries,
+			Timeout:     conf.Interval,
+		}))
+	}
+
+	// write the samples as Influx line protocol
+	if conf.InfluxURL != "" {
+		sink, err := influx.New(conf.InfluxURL, conf.InfluxToken, conf.Interval)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, sink)
+	}
+
+	// write the samples as DogStatsD gauges
+	if conf.StatsD != "" {
+		sink, err := statsd.New(conf.StatsD)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, sink)
+	}
+
+	// write the samples as JSON lines to a file or stdout
+	if conf.JSONLOutput != "" {
+		sink, err := jsonl.New(conf.JSONLOutput, int64(conf.JSONLSize)<<20, conf.JSONLFiles, conf.JSONLGzip)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, sink)
+	}
+
+	// emit Kubernetes events for storage threshold breaches
+	if conf.Events {
+		sink, err := newEventsSink(conf, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, sink)
+	}
+
+	// evaluate the alerting rules and notify the webhooks
+	if conf.AlertRules != "" {
+		rules, err := alerts.Load(conf.AlertRules)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, alerts.NewEngine(logger.Named("alerts"), rules))
+	}
+
+	return list, nil
+}
+
+// newEventsSink creates the Kubernetes events sink.
+func newEventsSink(conf *configs.Config, logger *zap.Logger) (*events.Sink, error) {
+	client, err := kube.NewInCluster(conf.APIServer)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := eventsConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return events.New(logger.Named("events"), client, cfg), nil
+}
+
+// eventsConfig returns the thresholds and the limits of the events sink.
+func eventsConfig(conf *configs.Config) (events.Config, error) {
+	var space int64
+	if conf.EventsSpace != "" {
+		var err error
+		space, err = kube.ParseQuantity(conf.EventsSpace)
+		if err != nil {
+			return events.Config{}, fmt.Errorf("failed to parse events volume threshold: %w", err)
+		}
+	}
+
+	// emit an event on the anomalies when the detector is enabled
+	var anomalies float64
+	if conf.Anomaly {
+		anomalies = conf.AnomalyThreshold
+	}
+
+	return events.Config{
+		AnomalyThreshold:   anomalies,
+		EphemeralRatio:     conf.EventsRatio,
+		VolumeMinAvailable: space,
+		Dedup:              conf.EventsDedup,
+		Rate:               conf.EventsRate,
+		PodCacheTTL:        time.Minute,
+	}, nil
+}
+
+// settings returns the reloadable options of the collectors.
+func settings(conf *configs.Config) collector.Settings {
+	return collector.Settings{
+		Interval:          conf.Interval,
+		Namespaces:        conf.Namespaces,
+		ExcludeNamespaces: conf.ExcludeNamespaces,
+	}
+}
+
+// reload applies the reloadable options of the configuration to the collectors and
+// to the running output sinks.
+func reload(conf *configs.Config, options *collector.Options, outputs []sinks.Sink, logger *zap.Logger) error {
+	// load everything first, so nothing is applied when a part is invalid
+	cfg, err := eventsConfig(conf)
+	if err != nil {
+		return err
+	}
+
+	rules := &alerts.Config{}
+	if conf.AlertRules != "" {
+		rules, err = alerts.Load(conf.AlertRules)
+		if err != nil {
+			return err
+		}
+	}
+
+	engine := false
+	for _, sink := range outputs {
+		switch sink := sink.(type) {
+		case *events.Sink:
+			sink.SetConfig(cfg)
+		case *alerts.Engine:
+			engine = true
+			sink.SetConfig(rules)
+		}
+	}
+
+	if !engine && conf.AlertRules != "" {
+		logger.Warn("alert rules are ignored until the next restart", zap.String("path", conf.AlertRules))
+	}
+
+	options.Set(settings(conf))
+
+	return nil
+}
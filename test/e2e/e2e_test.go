@@ -0,0 +1,250 @@
+package e2e
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/fakekubelet"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+)
+
+// timeout is the time a scenario is given to show in the scraped metrics.
+const timeout = 5 * time.Second
+
+// scenario returns a small scenario with the behaviors.
+func scenario(pods int, behaviors ...fakekubelet.Behavior) *fakekubelet.Scenario {
+	s := fakekubelet.DefaultScenario()
+	s.Pods = pods
+	s.Namespaces = []string{"default", "batch"}
+	s.Behaviors = behaviors
+
+	return s
+}
+
+// waitUp waits for the first snapshot of every pod of the fake kubelet.
+func (h *harness) waitUp() {
+	h.t.Helper()
+
+	h.eventually(timeout, func() error {
+		if up := h.up(); up != 1 {
+			return fmt.Errorf("api status is %g", up)
+		}
+
+		if got, want := len(h.pods()), len(h.kubelet.Pods()); got != want {
+			return fmt.Errorf("%d of %d pods exported", got, want)
+		}
+
+		return nil
+	})
+}
+
+// waitDown waits for the summary API status of the node to be down.
+func (h *harness) waitDown() {
+	h.t.Helper()
+
+	h.eventually(timeout, func() error {
+		if up := h.up(); up != 0 {
+			return fmt.Errorf("api status is %g", up)
+		}
+
+		return nil
+	})
+}
+
+func TestSeries(t *testing.T) {
+	h := newHarness(t, scenario(4))
+	h.waitUp()
+
+	for _, pod := range h.kubelet.Pods() {
+		namespace, name, _ := strings.Cut(pod, "/")
+		match := map[string]string{"exported_namespace": namespace, "exported_pod": name}
+
+		for _, def := range []*metrics.Definition{
+			metrics.EphemeralStorageUsageBytes,
+			metrics.EphemeralStorageCapacityBytes,
+			metrics.EphemeralStorageInodesUsed,
+			metrics.ContainerRootfsUsageBytes,
+			metrics.ContainerLogsUsageBytes,
+			metrics.PodVolumeUsageBytes,
+		} {
+			value, ok := h.value(def, match)
+			if !ok {
+				t.Errorf("%s of %s is not exported", def.FQName(), pod)
+			} else if value <= 0 {
+				t.Errorf("%s of %s is %g, expected a positive value", def.FQName(), pod, value)
+			}
+		}
+	}
+
+	if labels, _ := h.scrape(metrics.ContainerRootfsUsageBytes); len(labels) != 4*2 {
+		t.Errorf("expected 8 container series, got %d", len(labels))
+	}
+}
+
+func TestLinearGrowth(t *testing.T) {
+	h := newHarness(t, scenario(2, fakekubelet.Behavior{Kind: fakekubelet.KindLinearGrowth, Rate: "100Mi"}))
+	h.waitUp()
+
+	pod := h.kubelet.Pods()[0]
+	namespace, name, _ := strings.Cut(pod, "/")
+	match := map[string]string{"exported_namespace": namespace, "exported_pod": name}
+
+	before, _ := h.value(metrics.EphemeralStorageUsageBytes, match)
+
+	// 100Mi per second grows the pod by at least 10Mi in a few collections
+	h.eventually(timeout, func() error {
+		after, _ := h.value(metrics.EphemeralStorageUsageBytes, match)
+		if after-before < 10<<20 {
+			return fmt.Errorf("%s grew by %g bytes", pod, after-before)
+		}
+
+		return nil
+	})
+}
+
+func TestPodDeletion(t *testing.T) {
+	h := newHarness(t, scenario(5))
+	h.waitUp()
+
+	pods := h.kubelet.Pods()
+	namespace, name, _ := strings.Cut(pods[2], "/")
+	if !h.kubelet.DeletePod(namespace, name) {
+		t.Fatalf("pod %s not found", pods[2])
+	}
+
+	// the series of the deleted pod are dropped with the next snapshot
+	h.eventually(timeout, func() error {
+		exported := h.pods()
+		if exported[pods[2]] {
+			return fmt.Errorf("deleted pod %s is still exported", pods[2])
+		}
+
+		if len(exported) != 4 {
+			return fmt.Errorf("expected 4 pods, got %d", len(exported))
+		}
+
+		return nil
+	})
+
+	for _, def := range []*metrics.Definition{metrics.ContainerRootfsUsageBytes, metrics.PodVolumeUsageBytes} {
+		if _, ok := h.value(def, map[string]string{"exported_namespace": namespace, "exported_pod": name}); ok {
+			t.Errorf("%s of the deleted pod %s is still exported", def.FQName(), pods[2])
+		}
+	}
+}
+
+func TestPodChurn(t *testing.T) {
+	h := newHarness(t, scenario(6, fakekubelet.Behavior{
+		Kind:  fakekubelet.KindPodChurn,
+		Match: "batch/*",
+		Every: 200 * time.Millisecond,
+		Count: 1,
+	}))
+	h.waitUp()
+
+	initial := h.pods()
+
+	// the batch pods are replaced, and the default pods are kept
+	h.eventually(timeout, func() error {
+		exported := h.pods()
+		for pod := range initial {
+			if strings.HasPrefix(pod, "default/") && !exported[pod] {
+				return fmt.Errorf("default pod %s is not exported", pod)
+			}
+		}
+
+		replaced := 0
+		for pod := range exported {
+			if !initial[pod] {
+				replaced++
+			}
+		}
+
+		if replaced == 0 {
+			return fmt.Errorf("no batch pod was replaced")
+		}
+
+		return nil
+	})
+}
+
+func TestKubeletOutage(t *testing.T) {
+	h := newHarness(t, scenario(3))
+	h.waitUp()
+
+	t.Run("errors", func(t *testing.T) {
+		if err := h.kubelet.AddBehavior(fakekubelet.Behavior{Kind: fakekubelet.KindErrors, Duration: time.Second}); err != nil {
+			t.Fatal(err)
+		}
+
+		// the pod series are dropped while the summary API fails
+		h.waitDown()
+		if pods := h.pods(); len(pods) != 0 {
+			t.Errorf("expected no pods while the kubelet fails, got %d", len(pods))
+		}
+
+		h.waitUp()
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		h.stop()
+		h.waitDown()
+
+		h.restart()
+		h.waitUp()
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		if err := h.kubelet.AddBehavior(fakekubelet.Behavior{Kind: fakekubelet.KindSlow, Delay: 300 * time.Millisecond, Duration: 2 * time.Second}); err != nil {
+			t.Fatal(err)
+		}
+
+		h.eventually(timeout, func() error {
+			latency, _ := h.value(metrics.APILatency, nil)
+			if latency < 0.3 {
+				return fmt.Errorf("api latency is %gs", latency)
+			}
+
+			return nil
+		})
+	})
+}
+
+func TestCertRotation(t *testing.T) {
+	h := newHarness(t, scenario(3))
+	h.waitUp()
+
+	// the kubelet trusts a new CA, and rejects the current client certificate
+	next, err := fakekubelet.NewCA("e2e-ca-next")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.kubelet.SetClientCAs(next.Pool())
+	h.waitDown()
+
+	// the client certificate is rotated, and read by the next collection
+	h.writeClientCert(next)
+	h.waitUp()
+
+	// the serving certificate is rotated without interrupting the collection
+	serverCert, serverKey, err := next.Issue("fake-kubelet", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.kubelet.SetCertificate(cert)
+
+	time.Sleep(5 * interval)
+	if up := h.up(); up != 1 {
+		t.Errorf("api status is %g after the serving certificate rotation", up)
+	}
+}
@@ -0,0 +1,303 @@
+package e2e
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amirhnajafiz/localsight/internal/collector"
+	"github.com/amirhnajafiz/localsight/internal/fakekubelet"
+	"github.com/amirhnajafiz/localsight/internal/metrics"
+	"github.com/amirhnajafiz/localsight/internal/sinks"
+	"github.com/amirhnajafiz/localsight/internal/sources"
+
+	"github.com/prometheus/common/expfmt"
+	"go.uber.org/zap"
+)
+
+// interval is the collection interval of the tests.
+const interval = 50 * time.Millisecond
+
+// the metrics server and the metrics shared by the tests, and the number of harnesses
+var (
+	metricsURL string
+	mtx        *metrics.Metrics
+	harnesses  atomic.Int64
+)
+
+// TestMain starts the metrics server of the exporter on a random port.
+func TestMain(m *testing.M) {
+	var err error
+	if mtx, err = metrics.NewMetrics(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create metrics:", err)
+		os.Exit(1)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to find a free port:", err)
+		os.Exit(1)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	metrics.StartMetricsServer(zap.NewNop(), port)
+	metricsURL = fmt.Sprintf("http://127.0.0.1:%d/metrics", port)
+
+	// wait for the metrics server to listen
+	for range 100 {
+		if resp, err := http.Get(metricsURL); err == nil {
+			resp.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	os.Exit(m.Run())
+}
+
+// harness runs a fake kubelet that requires client certificates, and a collector of the
+// exporter that reads its summary and publishes the metrics.
+type harness struct {
+	t        *testing.T
+	node     string
+	kubelet  *fakekubelet.Server
+	addr     string
+	listener net.Listener
+	certFile string
+}
+
+// newHarness starts a fake kubelet of the scenario on a random port, with a server
+// certificate and a client certificate issued by a new CA, and the collector.
+func newHarness(t *testing.T, scenario *fakekubelet.Scenario) *harness {
+	t.Helper()
+
+	// every harness collects its own node, so the snapshots of the tests, and of the
+	// collectors of the previous runs, do not mix
+	scenario.NodeName = fmt.Sprintf("%s-%d", t.Name(), harnesses.Add(1))
+
+	ca, err := fakekubelet.NewCA("e2e-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverCert, serverKey, err := ca.Issue("fake-kubelet", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kubelet, err := fakekubelet.New(fakekubelet.Config{
+		Scenario:    scenario,
+		Certificate: &cert,
+		ClientCAs:   ca.Pool(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &harness{
+		t:        t,
+		node:     scenario.NodeName,
+		kubelet:  kubelet,
+		certFile: filepath.Join(t.TempDir(), "kubelet-client-current.pem"),
+	}
+	h.writeClientCert(ca)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.addr = l.Addr().String()
+	h.serve(l)
+
+	src, err := sources.NewKubelet("https://"+h.addr+"/stats/summary", h.certFile, h.certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	col := &collector.Collector{
+		NodeName: h.node,
+		Source:   src,
+		Logr:     zap.NewNop(),
+		Sinks:    sinks.NewFanOut(zap.NewNop(), mtx, time.Second, mtx),
+		Options:  collector.NewOptions(collector.Settings{Interval: interval}),
+	}
+
+	// stop the collector with the test, and drop the metrics of its node
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = col.Start(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		mtx.Remove(h.node)
+	})
+
+	return h
+}
+
+// serve serves the fake kubelet on the listener until the test ends.
+func (h *harness) serve(l net.Listener) {
+	h.listener = l
+	go h.kubelet.Serve(l)
+	h.t.Cleanup(func() { l.Close() })
+}
+
+// stop closes the listener of the fake kubelet.
+func (h *harness) stop() {
+	h.listener.Close()
+}
+
+// restart listens again on the address of the fake kubelet.
+func (h *harness) restart() {
+	h.t.Helper()
+
+	l, err := net.Listen("tcp", h.addr)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	h.serve(l)
+}
+
+// writeClientCert issues a client certificate of the CA, and writes it with its key to
+// the certificate file of the collector, like the kubelet client certificate rotation.
+func (h *harness) writeClientCert(ca *fakekubelet.CA) {
+	h.t.Helper()
+
+	cert, key, err := ca.Issue("system:node:" + h.node)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	tmp := h.certFile + ".tmp"
+	if err := os.WriteFile(tmp, append(cert, key...), 0o600); err != nil {
+		h.t.Fatal(err)
+	}
+
+	if err := os.Rename(tmp, h.certFile); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// series are the scraped samples of a metric, by their label values.
+type series []map[string]string
+
+// scrape reads the metrics server and returns the samples of the metric of the node.
+func (h *harness) scrape(def *metrics.Definition) (series, []float64) {
+	h.t.Helper()
+
+	resp, err := http.Get(metricsURL)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	var (
+		labels series
+		values []float64
+	)
+
+	family, ok := families[def.FQName()]
+	if !ok {
+		return nil, nil
+	}
+
+	for _, metric := range family.GetMetric() {
+		set := make(map[string]string)
+		for _, pair := range metric.GetLabel() {
+			set[pair.GetName()] = pair.GetValue()
+		}
+
+		if set["exported_node"] != h.node {
+			continue
+		}
+
+		labels = append(labels, set)
+		values = append(values, metric.GetGauge().GetValue())
+	}
+
+	return labels, values
+}
+
+// value returns the value of the sample of the metric with the labels.
+func (h *harness) value(def *metrics.Definition, match map[string]string) (float64, bool) {
+	labels, values := h.scrape(def)
+	for i, set := range labels {
+		if matches(set, match) {
+			return values[i], true
+		}
+	}
+
+	return 0, false
+}
+
+// up returns the summary API status of the node.
+func (h *harness) up() float64 {
+	value, _ := h.value(metrics.APIStatus, nil)
+	return value
+}
+
+// pods returns the namespace/name of the pods with an ephemeral storage sample.
+func (h *harness) pods() map[string]bool {
+	labels, _ := h.scrape(metrics.EphemeralStorageUsageBytes)
+
+	pods := make(map[string]bool, len(labels))
+	for _, set := range labels {
+		pods[set["exported_namespace"]+"/"+set["exported_pod"]] = true
+	}
+
+	return pods
+}
+
+// eventually retries the condition until it returns nil or the timeout passes.
+func (h *harness) eventually(timeout time.Duration, condition func() error) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := condition()
+		if err == nil {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			h.t.Fatalf("condition not met after %s: %v", timeout, err)
+		}
+
+		time.Sleep(interval / 2)
+	}
+}
+
+// matches returns true when the set has every label of the match.
+func matches(set, match map[string]string) bool {
+	for name, value := range match {
+		if set[name] != value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/amirhnajafiz/localsight/internal/configs"
+	"github.com/amirhnajafiz/localsight/internal/top"
+)
+
+// runTop shows a live dashboard of the node storage usage on the terminal.
+func runTop(args []string) error {
+	fs := flag.NewFlagSet("top", flag.ContinueOnError)
+	sort := fs.String("sort", top.SortEphemeral, "sort order of the pods: ephemeral, inodes, logs or growth")
+	namespace := fs.String("namespace", "", "show the pods of the namespaces that contain this value")
+
+	conf, _, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if conf.Mode != configs.ModeNode {
+		return fmt.Errorf("top shows a single node, use --source proxy --node-name <node> to watch a node through the API server")
+	}
+
+	src, err := newSource(conf)
+	if err != nil {
+		return fmt.Errorf("failed to create summary source: %w", err)
+	}
+
+	return top.Run(top.Config{
+		Source:    src,
+		Interval:  conf.Interval,
+		Sort:      *sort,
+		Namespace: *namespace,
+	})
+}